@@ -0,0 +1,498 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gowrite/export"
+	"gowrite/importer"
+	"gowrite/project"
+)
+
+// errUsage makes runCLI print the subcommand's usage line
+var errUsage = errors.New("usage")
+
+// flagError is a bad flag; runCLI reports it followed by the usage line
+type flagError struct{ err error }
+
+func (e flagError) Error() string { return e.err.Error() }
+func (e flagError) Unwrap() error { return errUsage }
+
+// cliCommand is a headless subcommand, run as `gowrite <name> <args>`
+type cliCommand struct {
+	usage string
+	run   func(args []string, stdout io.Writer) error
+}
+
+var cliCommands = map[string]cliCommand{
+	"export":     {"export <project> " + exportUsage, cliExport},
+	"wordcount":  {"wordcount <project> [--chapter N] [--json]", cliWordCount},
+	"stats":      {"stats <project> [--chapter N] [--json]", cliWordCount},
+	"analyze":    {"analyze <project> [--chapter N] [--json]", cliAnalyze},
+	"spellcheck": {"spellcheck <project> [--chapter N] [--dict file] [--json]", cliSpellCheck},
+	"import":     {"import <project> <file.txt|md|docx|odt|scriv> [--chapter N | --split [--pattern regex] [--notes]]", cliImport},
+}
+
+// cliOrder is the order subcommands are listed in the help text
+var cliOrder = []string{"export", "wordcount", "stats", "analyze", "spellcheck", "import"}
+
+// runCLI runs a headless subcommand. ok is false when args[0] is not a
+// subcommand, in which case the editor should start instead.
+func runCLI(args []string, stdout, stderr io.Writer) (code int, ok bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Fprintf(stdout, "usage: %s\n", editorUsage)
+		fmt.Fprintln(stdout, "\nWithout a command gowrite starts the editor. Commands:")
+		for _, name := range cliOrder {
+			fmt.Fprintf(stdout, "  gowrite %s\n", cliCommands[name].usage)
+		}
+		return 0, true
+	}
+
+	cmd, found := cliCommands[args[0]]
+	if !found {
+		return 0, false
+	}
+
+	err := cmd.run(args[1:], stdout)
+	switch {
+	case err == nil:
+		return 0, true
+	case errors.Is(err, errUsage):
+		if err != errUsage {
+			fmt.Fprintf(stderr, "gowrite %s: %v\n", args[0], err)
+		}
+		fmt.Fprintf(stderr, "usage: gowrite %s\n", cmd.usage)
+		return 2, true
+	default:
+		fmt.Fprintf(stderr, "gowrite %s: %v\n", args[0], err)
+		return 1, true
+	}
+}
+
+// parseFlags parses fs, allowing flags before, between and after the
+// positional arguments, which it returns.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, errUsage
+			}
+			return nil, flagError{err}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	return positional, nil
+}
+
+// editorArgs are the command line options for starting the editor
+type editorArgs struct {
+	Project string // project file to open, if any
+	Chapter int    // 1-based chapter to open; 0 restores the last one
+	View    string // "main", "notes" or "wiki"; empty restores the last one
+}
+
+const editorUsage = "gowrite [project] [--chapter N] [--view main|notes|wiki]"
+
+// parseEditorArgs reads the arguments gowrite was started with when they
+// are not a headless subcommand
+func parseEditorArgs(args []string) (editorArgs, error) {
+	var ea editorArgs
+	fs := flag.NewFlagSet("gowrite", flag.ContinueOnError)
+	fs.IntVar(&ea.Chapter, "chapter", 0, "chapter to open")
+	fs.StringVar(&ea.View, "view", "", "view to open")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return ea, err
+	}
+	if len(pos) > 1 {
+		return ea, errUsage
+	}
+	if len(pos) == 1 {
+		ea.Project = pos[0]
+	}
+	if ea.View != "" {
+		if _, ok := parseView(ea.View); !ok {
+			return ea, flagError{fmt.Errorf("unknown view %q", ea.View)}
+		}
+	}
+	if ea.Chapter < 0 {
+		return ea, flagError{fmt.Errorf("invalid chapter %d", ea.Chapter)}
+	}
+	return ea, nil
+}
+
+// chapterIndexes returns the chapters a command should cover: all of them,
+// or only the 1-based chapter n when n is set.
+func chapterIndexes(p *project.Project, n int) ([]int, error) {
+	if n == 0 {
+		idx := make([]int, len(p.Chapters))
+		for i := range idx {
+			idx[i] = i
+		}
+		return idx, nil
+	}
+	if n < 1 || n > len(p.Chapters) {
+		return nil, fmt.Errorf("chapter %d does not exist (project has %d)", n, len(p.Chapters))
+	}
+	return []int{n - 1}, nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// exportUsage is the argument syntax of export, in the editor and headless
+const exportUsage = "[format] <file> [range] [--notes quote|footnote] [--wiki] [--front-matter] [--font courier|times|helvetica] [--trim 6x9|a5|a4|letter|WxH] [--margin in] [--font-size pt] [--recto] [--no-headers] [--no-page-numbers] [--theme retro|dark|light]"
+
+// exportFlags registers the export options on fs
+func exportFlags(fs *flag.FlagSet) *export.Options {
+	opt := &export.Options{}
+	fs.StringVar(&opt.Notes, "notes", export.NotesNone, "include scene notes as a quote or footnote")
+	fs.BoolVar(&opt.Wiki, "wiki", false, "append the Story Wiki")
+	fs.BoolVar(&opt.FrontMatter, "front-matter", false, "start with YAML front matter")
+	fs.StringVar(&opt.Font, "font", "", "typeface for page based formats")
+	fs.StringVar(&opt.Trim, "trim", "", "PDF page size")
+	fs.Float64Var(&opt.Margin, "margin", 0, "PDF margins in inches")
+	fs.Float64Var(&opt.FontSize, "font-size", 0, "PDF body text size in points")
+	fs.BoolVar(&opt.Recto, "recto", false, "open PDF chapters on right-hand pages")
+	fs.BoolVar(&opt.NoHeaders, "no-headers", false, "leave running headers out of the PDF")
+	fs.BoolVar(&opt.NoPageNumbers, "no-page-numbers", false, "leave page numbers out of the PDF")
+	fs.StringVar(&opt.Theme, "theme", "", "colours of the html site")
+	return opt
+}
+
+// exportTarget reads the [format] <file> [range] arguments of export. The
+// format defaults to the one matching the file extension.
+func exportTarget(pos []string, opt *export.Options) (export.Format, string, error) {
+	format, explicit := export.Format{}, false
+	if len(pos) > 1 {
+		format, explicit = export.Lookup(pos[0])
+		if explicit {
+			pos = pos[1:]
+		}
+	}
+	if len(pos) < 1 || len(pos) > 2 {
+		return format, "", errUsage
+	}
+	if !explicit {
+		format = export.ForFile(pos[0])
+	}
+	if len(pos) == 2 {
+		var err error
+		if opt.First, opt.Last, err = export.ParseRange(pos[1]); err != nil {
+			return format, "", flagError{err}
+		}
+	}
+	return format, pos[0], nil
+}
+
+func cliExport(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	opt := exportFlags(fs)
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) < 2 {
+		return errUsage
+	}
+	format, filename, err := exportTarget(pos[1:], opt)
+	if err != nil {
+		return err
+	}
+
+	p, err := project.Load(pos[0])
+	if err != nil {
+		return err
+	}
+	written, err := export.File(filename, format, p, *opt)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Exported to %s\n", written)
+	return nil
+}
+
+type chapterStats struct {
+	Chapter int    `json:"chapter"`
+	Title   string `json:"title"`
+	Words   int    `json:"words"`
+	Chars   int    `json:"chars"`
+	Lines   int    `json:"lines"`
+	Target  int    `json:"target"`
+}
+
+func cliWordCount(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("wordcount", flag.ContinueOnError)
+	chapter := fs.Int("chapter", 0, "only count chapter N")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return errUsage
+	}
+
+	p, err := project.Load(pos[0])
+	if err != nil {
+		return err
+	}
+	indexes, err := chapterIndexes(p, *chapter)
+	if err != nil {
+		return err
+	}
+
+	report := struct {
+		Chapters []chapterStats `json:"chapters"`
+		Total    chapterStats   `json:"total"`
+	}{}
+	for _, i := range indexes {
+		c := p.Chapters[i]
+		st := CountText(c.Content)
+		report.Chapters = append(report.Chapters, chapterStats{
+			Chapter: i + 1, Title: c.Title,
+			Words: st.Words, Chars: st.Chars, Lines: st.Lines, Target: c.Target,
+		})
+		report.Total.Words += st.Words
+		report.Total.Chars += st.Chars
+		report.Total.Lines += st.Lines
+		report.Total.Target += c.Target
+	}
+	report.Total.Title = "Total"
+	if *chapter == 0 && p.Target > 0 {
+		report.Total.Target = p.Target
+	}
+
+	if *asJSON {
+		return writeJSON(stdout, report)
+	}
+	fmt.Fprintf(stdout, "%-4s %-40s %8s %8s %8s\n", "#", "Title", "Words", "Chars", "Target")
+	for _, c := range report.Chapters {
+		fmt.Fprintf(stdout, "%-4d %-40s %8d %8d %8d\n", c.Chapter, c.Title, c.Words, c.Chars, c.Target)
+	}
+	t := report.Total
+	fmt.Fprintf(stdout, "%-4s %-40s %8d %8d %8d\n", "", t.Title, t.Words, t.Chars, t.Target)
+	return nil
+}
+
+type chapterAnalysis struct {
+	Chapter     int    `json:"chapter"`
+	Title       string `json:"title"`
+	Grade       int    `json:"grade"`
+	Readability string `json:"readability"`
+	Sentences   int    `json:"sentences"`
+	Adverbs     int    `json:"adverbs"`
+	Passive     int    `json:"passive"`
+	Hard        int    `json:"hard"`
+	VeryHard    int    `json:"very_hard"`
+}
+
+func cliAnalyze(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	chapter := fs.Int("chapter", 0, "only analyze chapter N")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return errUsage
+	}
+
+	p, err := project.Load(pos[0])
+	if err != nil {
+		return err
+	}
+	indexes, err := chapterIndexes(p, *chapter)
+	if err != nil {
+		return err
+	}
+
+	var results []chapterAnalysis
+	for _, i := range indexes {
+		c := p.Chapters[i]
+		h := HemingwayStats(c.Content)
+		results = append(results, chapterAnalysis{
+			Chapter: i + 1, Title: c.Title,
+			Grade: ReadabilityGrade(c.Content), Readability: CalculateReadability(c.Content),
+			Sentences: h.Sentences, Adverbs: h.Adverbs, Passive: h.Passive, Hard: h.Hard, VeryHard: h.VeryHard,
+		})
+	}
+
+	if *asJSON {
+		return writeJSON(stdout, results)
+	}
+	for _, r := range results {
+		fmt.Fprintf(stdout, "Chapter %d: %s\n", r.Chapter, r.Title)
+		fmt.Fprintf(stdout, "  %s\n", r.Readability)
+		fmt.Fprintf(stdout, "  Sentences: %d  Adverbs: %d  Passive: %d  Hard: %d  Very hard: %d\n",
+			r.Sentences, r.Adverbs, r.Passive, r.Hard, r.VeryHard)
+	}
+	return nil
+}
+
+type chapterSpelling struct {
+	Chapter int      `json:"chapter"`
+	Title   string   `json:"title"`
+	Words   []string `json:"words"`
+}
+
+func cliSpellCheck(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("spellcheck", flag.ContinueOnError)
+	chapter := fs.Int("chapter", 0, "only check chapter N")
+	dict := fs.String("dict", DictionaryFile, "word list to check against")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return errUsage
+	}
+
+	dictionary, err := LoadDictionary(*dict)
+	if err != nil {
+		return err
+	}
+	p, err := project.Load(pos[0])
+	if err != nil {
+		return err
+	}
+	indexes, err := chapterIndexes(p, *chapter)
+	if err != nil {
+		return err
+	}
+
+	results := []chapterSpelling{}
+	for _, i := range indexes {
+		words := FindMisspellings(p.Chapters[i].Content, dictionary)
+		if len(words) > 0 {
+			results = append(results, chapterSpelling{Chapter: i + 1, Title: p.Chapters[i].Title, Words: words})
+		}
+	}
+
+	if *asJSON {
+		return writeJSON(stdout, results)
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(stdout, "No misspellings found!")
+	}
+	for _, r := range results {
+		fmt.Fprintf(stdout, "Chapter %d: %s\n", r.Chapter, r.Title)
+		for _, w := range r.Words {
+			fmt.Fprintf(stdout, "  %s\n", w)
+		}
+	}
+	return nil
+}
+
+func cliImport(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	chapter := fs.Int("chapter", 0, "overwrite chapter N instead of adding a new one")
+	split := fs.Bool("split", false, "split the file into chapters at its headings (always on for .docx and .odt)")
+	pattern := fs.String("pattern", "", "with --split, a regular expression matching chapter headings")
+	notes := fs.Bool("notes", false, "with --split, move comments and ::: notes sections into chapter notes")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 2 || (*split && *chapter > 0) {
+		return errUsage
+	}
+	opt := importer.Options{Notes: *notes}
+	if *pattern != "" {
+		if opt.Pattern, err = importer.ParsePattern(*pattern); err != nil {
+			return flagError{err}
+		}
+	}
+
+	fn, ok := validateImport(pos[1])
+	if !ok {
+		return errors.New("only .txt, .md, .docx, .odt and .scriv files supported for import")
+	}
+	// Documents and Scrivener projects are always split into chapters
+	if importer.IsDocument(fn) || importer.IsScrivener(fn) {
+		if *chapter > 0 {
+			return errors.New("documents are imported as new chapters; --chapter needs a .txt or .md file")
+		}
+		*split = true
+	}
+
+	// A missing project is created, with the imported file as its only chapter
+	p, err := project.Load(pos[0])
+	fresh := false
+	if errors.Is(err, os.ErrNotExist) && *chapter == 0 {
+		p, fresh = project.New(), true
+	} else if err != nil {
+		return err
+	}
+
+	if *split {
+		chapters, wiki, err := importer.Read(fn, opt)
+		if err != nil {
+			return err
+		}
+		if len(chapters) == 0 && len(wiki) == 0 {
+			return fmt.Errorf("nothing to import in %s", fn)
+		}
+		p.ImportChapters(chapters)
+		p.ImportWiki(wiki)
+		if err := p.Save(pos[0]); err != nil {
+			return err
+		}
+		msg := plural(len(chapters), "chapter", "chapters")
+		if len(wiki) > 0 {
+			msg += " and " + plural(len(wiki), "wiki entry", "wiki entries")
+		}
+		fmt.Fprintf(stdout, "Imported %s as %s of %s\n", fn, msg, p.Filename)
+		return nil
+	}
+
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+
+	title := strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn))
+	var idx int
+	switch {
+	case fresh:
+		p.Chapters[0] = project.Chapter{Title: title}
+	case *chapter > 0:
+		indexes, err := chapterIndexes(p, *chapter)
+		if err != nil {
+			return err
+		}
+		idx = indexes[0]
+	default:
+		idx = p.AddChapter(title)
+	}
+	p.Chapters[idx].Content = string(data)
+
+	if err := p.Save(pos[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Imported %s into Chapter %d of %s\n", fn, idx+1, p.Filename)
+	return nil
+}
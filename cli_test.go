@@ -0,0 +1,232 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gowrite/project"
+)
+
+func writeTestProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	p := project.New()
+	p.Chapters[0].Content = "The cat sat on the mat. He ran quickly."
+	p.Chapters[0].Target = 100
+	p.AddChapter("Second")
+	p.Chapters[1].Content = "It was kicked."
+	name := filepath.Join(dir, "book.json")
+	if err := p.Save(name); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestRunCLI_NotACommand(t *testing.T) {
+	for _, args := range [][]string{nil, {"mybook.json"}} {
+		if _, ok := runCLI(args, &bytes.Buffer{}, &bytes.Buffer{}); ok {
+			t.Errorf("runCLI(%v) handled, want editor to start", args)
+		}
+	}
+}
+
+func TestRunCLI_WordCountJSON(t *testing.T) {
+	name := writeTestProject(t)
+	var out bytes.Buffer
+	code, ok := runCLI([]string{"wordcount", name, "--json"}, &out, &bytes.Buffer{})
+	if !ok || code != 0 {
+		t.Fatalf("runCLI() = %d, %v", code, ok)
+	}
+
+	var report struct {
+		Chapters []chapterStats
+		Total    chapterStats
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(report.Chapters) != 2 || report.Total.Words != 12 || report.Total.Target != 100 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestRunCLI_ChapterFlag(t *testing.T) {
+	name := writeTestProject(t)
+	var out bytes.Buffer
+	runCLI([]string{"analyze", "--chapter", "2", name}, &out, &bytes.Buffer{})
+	if !strings.Contains(out.String(), "Chapter 2: Second") || strings.Contains(out.String(), "Chapter 1") {
+		t.Errorf("analyze --chapter 2 output:\n%s", out.String())
+	}
+
+	var stderr bytes.Buffer
+	if code, _ := runCLI([]string{"analyze", "--chapter", "9", name}, &out, &stderr); code != 1 {
+		t.Errorf("missing chapter: code = %d, stderr = %q", code, stderr.String())
+	}
+}
+
+func TestRunCLI_Usage(t *testing.T) {
+	var stderr bytes.Buffer
+	code, ok := runCLI([]string{"export", "only-one-arg"}, &bytes.Buffer{}, &stderr)
+	if !ok || code != 2 || !strings.Contains(stderr.String(), "usage: gowrite export") {
+		t.Errorf("runCLI() = %d, %v; stderr = %q", code, ok, stderr.String())
+	}
+}
+
+func TestRunCLI_ExportAndImport(t *testing.T) {
+	name := writeTestProject(t)
+	dir := filepath.Dir(name)
+
+	out := filepath.Join(dir, "manuscript")
+	if code, _ := runCLI([]string{"export", name, out}, &bytes.Buffer{}, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("export code = %d", code)
+	}
+	data, err := os.ReadFile(out + ".txt")
+	if err != nil || !strings.Contains(string(data), "# Chapter 2: Second") {
+		t.Fatalf("export output = %q, %v", data, err)
+	}
+
+	txt := filepath.Join(dir, "epilogue.txt")
+	os.WriteFile(txt, []byte("The end."), 0644)
+	if code, _ := runCLI([]string{"import", name, txt}, &bytes.Buffer{}, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("import code = %d", code)
+	}
+	p, err := project.Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Chapters) != 3 || p.Chapters[2].Title != "epilogue" || p.Chapters[2].Content != "The end." {
+		t.Errorf("chapters after import = %+v", p.Chapters)
+	}
+}
+
+func TestRunCLI_ImportSplit(t *testing.T) {
+	dir := t.TempDir()
+	md := filepath.Join(dir, "book.md")
+	os.WriteFile(md, []byte("Chapter 1\nDawn.\n\nChapter 2\nDusk.\n"), 0644)
+	name := filepath.Join(dir, "new.json")
+
+	var stderr bytes.Buffer
+	code, _ := runCLI([]string{"import", name, md, "--split", "--pattern", `^Chapter \d+`}, &bytes.Buffer{}, &stderr)
+	if code != 0 {
+		t.Fatalf("import code = %d: %s", code, stderr.String())
+	}
+	p, err := project.Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Chapters) != 2 || p.Chapters[1].Title != "Chapter 2" || p.Chapters[1].Content != "Dusk." {
+		t.Errorf("chapters after import = %+v", p.Chapters)
+	}
+
+	if code, _ := runCLI([]string{"import", name, md, "--split", "--pattern", "("}, &bytes.Buffer{}, &bytes.Buffer{}); code != 2 {
+		t.Errorf("bad pattern: code = %d, want 2", code)
+	}
+}
+
+func TestRunCLI_ImportDocument(t *testing.T) {
+	name := writeTestProject(t)
+	docx := filepath.Join(filepath.Dir(name), "draft.docx")
+	f, _ := os.Create(docx)
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("word/document.xml")
+	heading := `<w:p><w:pPr><w:outlineLvl w:val="0"/></w:pPr><w:r><w:t>%s</w:t></w:r></w:p><w:p><w:r><w:t>Text.</w:t></w:r></w:p>`
+	w.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		strings.ReplaceAll(heading, "%s", "Three") + strings.ReplaceAll(heading, "%s", "Four") + `</w:body></w:document>`))
+	zw.Close()
+	f.Close()
+
+	if code, _ := runCLI([]string{"import", name, docx, "--chapter", "1"}, &bytes.Buffer{}, &bytes.Buffer{}); code != 1 {
+		t.Errorf("--chapter with a document: code = %d, want 1", code)
+	}
+	var stdout bytes.Buffer
+	if code, _ := runCLI([]string{"import", name, docx}, &stdout, &bytes.Buffer{}); code != 0 || !strings.Contains(stdout.String(), "as 2 chapters") {
+		t.Fatalf("import code = %d, output %q", code, stdout.String())
+	}
+	p, err := project.Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Chapters) != 4 || p.Chapters[3].Title != "Four" || p.Chapters[3].Content != "Text." {
+		t.Errorf("chapters after import = %+v", p.Chapters)
+	}
+}
+
+func TestRunCLI_ImportScrivener(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Book.scriv")
+	os.MkdirAll(filepath.Join(dir, "Files", "Data", "C"), 0755)
+	os.WriteFile(filepath.Join(dir, "Book.scrivx"), []byte(`<ScrivenerProject><Binder>
+<BinderItem UUID="D" Type="DraftFolder"><Title>Draft</Title><Children><BinderItem UUID="C" Type="Text"><Title>One</Title></BinderItem></Children></BinderItem>
+<BinderItem UUID="R" Type="ResearchFolder"><Title>Research</Title><Children><BinderItem UUID="C" Type="Text"><Title>Notes</Title></BinderItem></Children></BinderItem>
+</Binder></ScrivenerProject>`), 0644)
+	os.WriteFile(filepath.Join(dir, "Files", "Data", "C", "content.rtf"), []byte(`{\rtf1 Text.}`), 0644)
+
+	name := filepath.Join(filepath.Dir(dir), "book.json")
+	var stdout bytes.Buffer
+	if code, _ := runCLI([]string{"import", name, dir}, &stdout, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("import code = %d", code)
+	}
+	if want := "as 1 chapter and 1 wiki entry"; !strings.Contains(stdout.String(), want) {
+		t.Errorf("output %q lacks %q", stdout.String(), want)
+	}
+	p, err := project.Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Chapters) != 1 || p.Chapters[0].Content != "Text." || len(p.Wiki) != 1 || p.Wiki[0].Title != "Notes" {
+		t.Errorf("project after import = %+v", p)
+	}
+}
+
+func TestRunCLI_ExportMarkdownRange(t *testing.T) {
+	name := writeTestProject(t)
+	out := filepath.Join(filepath.Dir(name), "part")
+
+	var stderr bytes.Buffer
+	code, _ := runCLI([]string{"export", name, "md", out, "2-2", "--front-matter"}, &bytes.Buffer{}, &stderr)
+	if code != 0 {
+		t.Fatalf("export code = %d: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(out + ".md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "---\ntitle: \"book\"\n---\n\n# Second\n\nIt was kicked.\n\n"; string(data) != want {
+		t.Errorf("export output = %q, want %q", data, want)
+	}
+
+	if code, _ := runCLI([]string{"export", name, out + ".md", "3-9"}, &bytes.Buffer{}, &stderr); code != 1 {
+		t.Errorf("out of range export code = %d, want 1", code)
+	}
+	if code, _ := runCLI([]string{"export", name, out, "x"}, &bytes.Buffer{}, &stderr); code != 2 {
+		t.Errorf("bad range export code = %d, want 2", code)
+	}
+}
+
+func TestParseEditorArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    editorArgs
+		wantErr bool
+	}{
+		{nil, editorArgs{}, false},
+		{[]string{"mybook.json"}, editorArgs{Project: "mybook.json"}, false},
+		{[]string{"--chapter", "3", "mybook.json", "--view", "wiki"}, editorArgs{Project: "mybook.json", Chapter: 3, View: "wiki"}, false},
+		{[]string{"a.json", "b.json"}, editorArgs{}, true},
+		{[]string{"a.json", "--view", "analyze"}, editorArgs{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseEditorArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseEditorArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseEditorArgs(%v) = %+v, want %+v", tt.args, got, tt.want)
+		}
+	}
+}
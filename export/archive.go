@@ -0,0 +1,33 @@
+package export
+
+import (
+	"archive/zip"
+	"io"
+)
+
+// zipFile is one entry of a zip based format (EPUB, DOCX)
+type zipFile struct {
+	Name  string
+	Data  string
+	Store bool // leave uncompressed
+}
+
+// writeZip writes files to w as a zip archive, in order
+func writeZip(w io.Writer, files []zipFile) error {
+	z := zip.NewWriter(w)
+	modified := now()
+	for _, f := range files {
+		method := zip.Deflate
+		if f.Store {
+			method = zip.Store
+		}
+		fw, err := z.CreateHeader(&zip.FileHeader{Name: f.Name, Method: method, Modified: modified})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.Data); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
@@ -0,0 +1,244 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"gowrite/project"
+)
+
+// Page geometry of a standard manuscript, in twentieths of a point: US
+// Letter with one inch margins
+const (
+	docxPageWidth  = 12240
+	docxPageHeight = 15840
+	docxMargin     = 1440
+	docxTextWidth  = docxPageWidth - 2*docxMargin
+)
+
+// docxFonts maps the --font names to the typefaces a manuscript may use
+var docxFonts = map[string]string{
+	"":        "Courier New",
+	"courier": "Courier New",
+	"times":   "Times New Roman",
+}
+
+// Paragraph properties used by the manuscript
+const (
+	docxSingle   = `<w:spacing w:line="240" w:lineRule="auto"/><w:ind w:firstLine="0"/>`
+	docxCentered = `<w:ind w:firstLine="0"/><w:jc w:val="center"/>`
+	docxChapter  = `<w:pageBreakBefore/><w:spacing w:before="2880"/>` + docxCentered
+)
+
+// DOCX writes the manuscript as a Word document in standard manuscript
+// format (after William Shunn): 12pt Courier or Times, double spaced, one
+// inch margins, a cover page with contact details and a rounded word count,
+// each chapter on a new page and a "Surname / Title / page" header.
+func DOCX(w io.Writer, p *project.Project, opt Options) error {
+	font, ok := docxFonts[strings.ToLower(opt.Font)]
+	if !ok {
+		return fmt.Errorf("unknown font %q for docx (use courier or times)", opt.Font)
+	}
+	idx, err := opt.Chapters(p)
+	if err != nil {
+		return err
+	}
+
+	words := 0
+	for _, i := range idx {
+		words += len(strings.Fields(p.Chapters[i].Content))
+	}
+
+	var body strings.Builder
+	docxCover(&body, p, words)
+	for _, i := range idx {
+		chap := p.Chapters[i]
+		docxParagraph(&body, docxChapter, docxRuns(fmt.Sprintf("Chapter %d", i+1)))
+		if chap.Title != "" {
+			docxParagraph(&body, docxCentered, docxRuns(chap.Title))
+		}
+		docxProse(&body, chap.Content)
+	}
+	docxParagraph(&body, docxCentered, docxRuns("END"))
+
+	files := []zipFile{
+		{Name: "[Content_Types].xml", Data: docxContentTypes},
+		{Name: "_rels/.rels", Data: docxRels},
+		{Name: "docProps/core.xml", Data: docxCore(p)},
+		{Name: "word/_rels/document.xml.rels", Data: docxDocumentRels},
+		{Name: "word/styles.xml", Data: fmt.Sprintf(docxStyles, font, font, font, font)},
+		{Name: "word/header1.xml", Data: docxHeader(p)},
+		{Name: "word/document.xml", Data: fmt.Sprintf(docxDocument, body.String(), docxPageWidth, docxPageHeight, docxMargin, docxMargin, docxMargin, docxMargin)},
+	}
+	return writeZip(w, files)
+}
+
+// RoundWords rounds a word count the way a manuscript cover reports it:
+// to the nearest 100 for short fiction, 500 up to a novella and 1,000 for
+// a novel
+func RoundWords(n int) int {
+	step := 1000
+	switch {
+	case n < 17500:
+		step = 100
+	case n < 40000:
+		step = 500
+	}
+	rounded := (n + step/2) / step * step
+	if rounded == 0 {
+		rounded = step
+	}
+	return rounded
+}
+
+// docxCover writes the cover page: contact details top left, word count top
+// right, and title and byline centered halfway down
+func docxCover(b *strings.Builder, p *project.Project, words int) {
+	contact := strings.Split(strings.TrimSpace(p.Metadata.Contact), "\n")
+	if contact[0] == "" {
+		contact[0] = p.Metadata.Author
+	}
+	count := fmt.Sprintf("about %s words", thousands(RoundWords(words)))
+
+	tab := fmt.Sprintf(`<w:tabs><w:tab w:val="right" w:pos="%d"/></w:tabs>`, docxTextWidth)
+	docxParagraph(b, tab+docxSingle, docxRuns(strings.TrimSpace(contact[0]))+`<w:r><w:tab/></w:r>`+docxRuns(count))
+	for _, line := range contact[1:] {
+		docxParagraph(b, docxSingle, docxRuns(strings.TrimSpace(line)))
+	}
+
+	docxParagraph(b, `<w:spacing w:before="4320"/>`+docxCentered, docxRuns(p.Name()))
+	if p.Metadata.Author != "" {
+		docxParagraph(b, docxCentered, docxRuns("by "+p.Metadata.Author))
+	}
+}
+
+// docxProse writes chapter text: a paragraph per line, centered "#" for
+// scene breaks
+func docxProse(b *strings.Builder, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case isSceneBreak(line):
+			docxParagraph(b, docxCentered, docxRuns("#"))
+		default:
+			docxParagraph(b, "", docxRuns(line))
+		}
+	}
+}
+
+// docxParagraph writes a paragraph with properties pPr holding runs
+func docxParagraph(b *strings.Builder, pPr, runs string) {
+	b.WriteString("<w:p>")
+	if pPr != "" {
+		b.WriteString("<w:pPr>" + pPr + "</w:pPr>")
+	}
+	b.WriteString(runs + "</w:p>\n")
+}
+
+// docxRuns turns a line of prose into runs, italic and bold where the
+// inline Markdown says so
+func docxRuns(line string) string {
+	var b strings.Builder
+	for _, s := range spans(line) {
+		b.WriteString("<w:r>")
+		if s.Italic || s.Bold {
+			b.WriteString("<w:rPr>")
+			if s.Bold {
+				b.WriteString("<w:b/>")
+			}
+			if s.Italic {
+				b.WriteString("<w:i/>")
+			}
+			b.WriteString("</w:rPr>")
+		}
+		b.WriteString(`<w:t xml:space="preserve">` + html.EscapeString(s.Text) + "</w:t></w:r>")
+	}
+	return b.String()
+}
+
+// docxHeader is the running header of every page but the cover
+func docxHeader(p *project.Project) string {
+	label := p.Name() + " / "
+	if fields := strings.Fields(p.Metadata.Author); len(fields) > 0 {
+		label = fields[len(fields)-1] + " / " + label
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:p><w:pPr>%s<w:jc w:val="right"/></w:pPr>%s<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> PAGE </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>2</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>
+</w:hdr>
+`, docxSingle, docxRuns(label))
+}
+
+// docxCore holds the document properties Word shows as title and author
+func docxCore(p *project.Project) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<dc:title>%s</dc:title>
+<dc:creator>%s</dc:creator>
+<dcterms:created xsi:type="dcterms:W3CDTF">%s</dcterms:created>
+</cp:coreProperties>
+`, html.EscapeString(p.Name()), html.EscapeString(p.Metadata.Author), now().UTC().Format("2006-01-02T15:04:05Z"))
+}
+
+// thousands formats n with comma separators: 80000 → "80,000"
+func thousands(n int) string {
+	s := fmt.Sprint(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>
+<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>
+</Types>
+`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>
+</Relationships>
+`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>
+</Relationships>
+`
+
+// docxStyles makes every paragraph 12pt, double spaced with a half inch
+// first line indent unless it says otherwise
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:docDefaults>
+<w:rPrDefault><w:rPr><w:rFonts w:ascii="%s" w:hAnsi="%s" w:cs="%s" w:eastAsia="%s"/><w:sz w:val="24"/><w:szCs w:val="24"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:before="0" w:after="0" w:line="480" w:lineRule="auto"/><w:ind w:firstLine="720"/></w:pPr></w:pPrDefault>
+</w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+</w:styles>
+`
+
+// docxDocument is the main part; titlePg leaves the header off the cover
+const docxDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<w:body>
+%s<w:sectPr>
+<w:headerReference w:type="default" r:id="rId2"/>
+<w:pgSz w:w="%d" w:h="%d"/>
+<w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="720" w:footer="720" w:gutter="0"/>
+<w:titlePg/>
+</w:sectPr>
+</w:body>
+</w:document>
+`
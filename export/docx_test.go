@@ -0,0 +1,151 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// unzip returns the entries of a zip based export, checking every XML part
+// is well-formed
+func unzip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(content)
+
+		if strings.HasSuffix(f.Name, "xml") || strings.HasSuffix(f.Name, ".rels") || strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".opf") {
+			d := xml.NewDecoder(bytes.NewReader(content))
+			d.Entity = xml.HTMLEntity
+			for {
+				if _, err := d.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Errorf("%s is not well-formed: %v", f.Name, err)
+					break
+				}
+			}
+		}
+	}
+	return files
+}
+
+// pPrOrder is the order the schema wants the paragraph properties we write
+// in; Word refuses a document that has them out of order
+var pPrOrder = []string{"pageBreakBefore", "tabs", "spacing", "ind", "jc"}
+
+// checkPPrOrder checks the children of every w:pPr in doc are in schema order
+func checkPPrOrder(t *testing.T, name, doc string) {
+	t.Helper()
+	rank := make(map[string]int)
+	for i, el := range pPrOrder {
+		rank[el] = i + 1
+	}
+	d := xml.NewDecoder(strings.NewReader(doc))
+	depth, last := 0, 0 // depth inside a w:pPr, rank of its previous child
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case depth > 0:
+				depth++
+				if depth > 2 {
+					break
+				}
+				r, ok := rank[tok.Name.Local]
+				if !ok {
+					t.Errorf("%s: unexpected <w:%s> in a pPr", name, tok.Name.Local)
+				} else if r < last {
+					t.Errorf("%s: <w:%s> after <w:%s> in a pPr", name, tok.Name.Local, pPrOrder[last-1])
+				}
+				last = r
+			case tok.Name.Local == "pPr":
+				depth, last = 1, 0
+			}
+		case xml.EndElement:
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+}
+
+func TestDOCX(t *testing.T) {
+	p := markdownProject()
+	p.Metadata.Contact = "Jane Q. Doe\n1 Main St\njane@example.com"
+	p.Chapters[1].Content = "It was *very* quiet.\n\n#\n\nThe end & more."
+
+	var buf bytes.Buffer
+	if err := DOCX(&buf, p, Options{Font: "times"}); err != nil {
+		t.Fatal(err)
+	}
+	files := unzip(t, buf.Bytes())
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "word/_rels/document.xml.rels", "word/styles.xml", "word/header1.xml", "docProps/core.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	doc := files["word/document.xml"]
+	for _, want := range []string{
+		"Jane Q. Doe</w:t></w:r><w:r><w:tab/></w:r>",
+		"about 100 words",
+		"jane@example.com",
+		">by Jane Doe<",
+		">Chapter 2<",
+		`<w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">very</w:t></w:r>`,
+		"The end &amp; more.",
+		">#<",
+		">END<",
+		"<w:titlePg/>",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document.xml lacks %s", want)
+		}
+	}
+	if n := strings.Count(doc, "<w:pageBreakBefore/>"); n != 2 {
+		t.Errorf("%d chapters start a page, want 2", n)
+	}
+	if !strings.Contains(files["word/header1.xml"], "Doe / The Call / ") {
+		t.Errorf("header = %s", files["word/header1.xml"])
+	}
+	if !strings.Contains(files["word/styles.xml"], `w:ascii="Times New Roman"`) || !strings.Contains(files["word/styles.xml"], `w:line="480"`) {
+		t.Errorf("styles = %s", files["word/styles.xml"])
+	}
+
+	for _, name := range []string{"word/document.xml", "word/header1.xml", "word/styles.xml"} {
+		checkPPrOrder(t, name, files[name])
+	}
+
+	if err := DOCX(&buf, p, Options{Font: "comic sans"}); err == nil {
+		t.Error("unknown font: no error")
+	}
+}
+
+func TestRoundWords(t *testing.T) {
+	for n, want := range map[int]int{0: 100, 3: 100, 4349: 4300, 4350: 4400, 23240: 23000, 23260: 23500, 81499: 81000, 81500: 82000} {
+		if got := RoundWords(n); got != want {
+			t.Errorf("RoundWords(%d) = %d, want %d", n, got, want)
+		}
+	}
+	if got := thousands(1234567); got != "1,234,567" {
+		t.Errorf("thousands() = %q", got)
+	}
+}
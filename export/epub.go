@@ -0,0 +1,149 @@
+package export
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"gowrite/project"
+)
+
+// epubCSS is the only stylesheet; the book needs nothing from outside it
+const epubCSS = `body { font-family: serif; line-height: 1.5; margin: 0 5%; }
+h1 { text-align: center; margin: 2em 0 1em; page-break-before: always; }
+p { margin: 0; text-indent: 1.5em; text-align: justify; }
+h1 + p, hr + p { text-indent: 0; }
+hr.scene-break { border: none; margin: 1em 0; text-align: center; }
+hr.scene-break::after { content: "* * *"; }
+.title-page { text-align: center; margin-top: 30%; }
+.title-page p { text-indent: 0; text-align: center; }
+nav ol { list-style: none; padding: 0; }
+`
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// epubPage is one XHTML document in the book
+type epubPage struct {
+	File  string
+	Title string
+	Body  string
+}
+
+// EPUB writes the manuscript as an EPUB 3 book: a title page, one XHTML
+// document per chapter and, when opt.Wiki is set, the Story Wiki as an
+// appendix. The table of contents is built from the chapter titles.
+func EPUB(w io.Writer, p *project.Project, opt Options) error {
+	idx, err := opt.Chapters(p)
+	if err != nil {
+		return err
+	}
+	title, author := p.Name(), p.Metadata.Author
+
+	titleBody := fmt.Sprintf("<div class=\"title-page\">\n<h1>%s</h1>\n", html.EscapeString(title))
+	if author != "" {
+		titleBody += fmt.Sprintf("<p>%s</p>\n", html.EscapeString(author))
+	}
+	pages := []epubPage{{File: "title.xhtml", Title: title, Body: titleBody + "</div>\n"}}
+
+	for _, i := range idx {
+		chap := p.Chapters[i]
+		pages = append(pages, epubPage{
+			File:  fmt.Sprintf("chapter-%d.xhtml", i+1),
+			Title: chap.Title,
+			Body:  fmt.Sprintf("<h1>%s</h1>\n%s", html.EscapeString(chap.Title), proseHTML(chap.Content)),
+		})
+	}
+	if opt.Wiki {
+		var b strings.Builder
+		b.WriteString("<h1>Appendix: Story Wiki</h1>\n")
+		for _, entry := range p.Wiki {
+			fmt.Fprintf(&b, "<h2>%s</h2>\n%s", html.EscapeString(entry.Title), proseHTML(entry.Content))
+		}
+		pages = append(pages, epubPage{File: "appendix.xhtml", Title: "Appendix: Story Wiki", Body: b.String()})
+	}
+
+	files := []zipFile{
+		// The mimetype must come first and be stored uncompressed
+		{Name: "mimetype", Data: "application/epub+zip", Store: true},
+		{Name: "META-INF/container.xml", Data: epubContainer},
+		{Name: "OEBPS/content.opf", Data: epubPackage(title, author, pages)},
+		{Name: "OEBPS/nav.xhtml", Data: epubNav(pages[1:])},
+		{Name: "OEBPS/style.css", Data: epubCSS},
+	}
+	for _, pg := range pages {
+		files = append(files, zipFile{Name: "OEBPS/" + pg.File, Data: xhtmlPage(pg.Title, pg.Body, "")})
+	}
+	return writeZip(w, files)
+}
+
+// xhtmlPage wraps body in an XHTML 5 document linking the book stylesheet
+func xhtmlPage(title, body, extraNS string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml"%s xml:lang="en" lang="en">
+<head>
+<meta charset="UTF-8"/>
+<title>%s</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+%s</body>
+</html>
+`, extraNS, html.EscapeString(title), body)
+}
+
+// epubPackage builds the OPF package document: metadata, manifest and spine
+func epubPackage(title, author string, pages []epubPage) string {
+	// A name based (version 5) UUID, so re-exports update the same book
+	sum := sha1.Sum([]byte("gowrite\x00" + title + "\x00" + author))
+	u := sum[:16]
+	u[6] = u[6]&0x0f | 0x50
+	u[8] = u[8]&0x3f | 0x80
+	id := fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="en">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">%s</dc:identifier>
+    <dc:title>%s</dc:title>
+`, id, html.EscapeString(title))
+	if author != "" {
+		fmt.Fprintf(&b, "    <dc:creator>%s</dc:creator>\n", html.EscapeString(author))
+	}
+	fmt.Fprintf(&b, `    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">%s</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="css" href="style.css" media-type="text/css"/>
+`, now().UTC().Format("2006-01-02T15:04:05Z"))
+	for i, pg := range pages {
+		fmt.Fprintf(&b, "    <item id=\"page-%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i, pg.File)
+	}
+	b.WriteString("  </manifest>\n  <spine>\n")
+	for i := range pages {
+		fmt.Fprintf(&b, "    <itemref idref=\"page-%d\"/>\n", i)
+	}
+	b.WriteString("  </spine>\n</package>\n")
+	return b.String()
+}
+
+// epubNav builds the navigation document holding the table of contents
+func epubNav(pages []epubPage) string {
+	var b strings.Builder
+	b.WriteString("<nav epub:type=\"toc\" id=\"toc\">\n<h1>Contents</h1>\n<ol>\n")
+	for _, pg := range pages {
+		fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", pg.File, html.EscapeString(pg.Title))
+	}
+	b.WriteString("</ol>\n</nav>\n")
+	return xhtmlPage("Contents", b.String(), ` xmlns:epub="http://www.idpf.org/2007/ops"`)
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gowrite/project"
+)
+
+func TestEPUB(t *testing.T) {
+	now = func() time.Time { return time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	p := markdownProject()
+	p.Chapters[1].Title = "Cats & Dogs"
+	var buf bytes.Buffer
+	if err := EPUB(&buf, p, Options{Wiki: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := z.File[0]; f.Name != "mimetype" || f.Method != zip.Store {
+		t.Errorf("first entry = %s (method %d), want stored mimetype", f.Name, f.Method)
+	}
+	files := unzip(t, buf.Bytes())
+
+	for _, name := range []string{"META-INF/container.xml", "OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/title.xhtml", "OEBPS/chapter-1.xhtml", "OEBPS/chapter-2.xhtml", "OEBPS/appendix.xhtml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	opf := files["OEBPS/content.opf"]
+	for _, want := range []string{"<dc:title>The Call</dc:title>", "<dc:creator>Jane Doe</dc:creator>", "2026-10-17T15:00:00Z", `properties="nav"`} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("content.opf lacks %s", want)
+		}
+	}
+	if nav := files["OEBPS/nav.xhtml"]; !strings.Contains(nav, `<a href="chapter-2.xhtml">Cats &amp; Dogs</a>`) {
+		t.Errorf("nav.xhtml = %s", nav)
+	}
+	if ch := files["OEBPS/chapter-1.xhtml"]; !strings.Contains(ch, "<p>The phone rang.</p>") {
+		t.Errorf("chapter-1.xhtml = %s", ch)
+	}
+}
+
+func TestEPUB_Range(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EPUB(&buf, markdownProject(), Options{First: 2}); err != nil {
+		t.Fatal(err)
+	}
+	z, _ := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	for _, f := range z.File {
+		if f.Name == "OEBPS/chapter-1.xhtml" {
+			t.Error("chapter 1 exported outside the range")
+		}
+	}
+	if err := EPUB(&buf, project.New(), Options{First: 3}); err == nil {
+		t.Error("out of range export: no error")
+	}
+}
@@ -0,0 +1,189 @@
+// Package export writes a project out in formats other than gowrite's own
+// project JSON.
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gowrite/project"
+)
+
+// now is replaced in tests
+var now = time.Now
+
+// How scene notes are included in an export
+const (
+	NotesNone     = ""
+	NotesQuote    = "quote"    // a blockquote under the chapter heading
+	NotesFootnote = "footnote" // a footnote on the chapter heading
+)
+
+// Options control what an export contains. Formats ignore options they have
+// no use for.
+type Options struct {
+	First, Last int    // 1-based chapter range; 0 means from the start / to the end
+	Notes       string // NotesNone, NotesQuote or NotesFootnote
+	Wiki        bool   // append the Story Wiki
+	FrontMatter bool   // start with YAML front matter (title, author)
+	Font        string // typeface for page based formats, e.g. "courier" or "times"
+
+	// Page layout of PDF
+	Trim          string  // page size, e.g. "6x9" or "a5"; see ParseTrim
+	Margin        float64 // inches; 0 means the default
+	FontSize      float64 // points; 0 means the default
+	Recto         bool    // open chapters on right-hand pages
+	NoHeaders     bool    // leave out running headers
+	NoPageNumbers bool
+
+	Theme string // colours of the html site: retro, dark or light
+}
+
+// Format is a file format a project can be exported to. A format writes
+// either one file or, with WriteDir, a directory of them.
+type Format struct {
+	Name     string // as typed in 'export <format> ...'
+	Ext      string // default file extension; none for directories
+	Write    func(w io.Writer, p *project.Project, opt Options) error
+	WriteDir func(dir string, p *project.Project, opt Options) error
+}
+
+// formats are searched in order; the first is the default
+var formats []Format
+
+// register adds a format to the list Lookup and ForFile search
+func register(f Format) {
+	formats = append(formats, f)
+}
+
+func init() {
+	register(Format{Name: "txt", Ext: ".txt", Write: Text})
+	register(Format{Name: "md", Ext: ".md", Write: Markdown})
+	register(Format{Name: "epub", Ext: ".epub", Write: EPUB})
+	register(Format{Name: "docx", Ext: ".docx", Write: DOCX})
+	register(Format{Name: "pdf", Ext: ".pdf", Write: PDF})
+	register(Format{Name: "html", WriteDir: Site})
+	register(Format{Name: "fountain", Ext: ".fountain", Write: Fountain})
+}
+
+// Lookup returns the format called name
+func Lookup(name string) (Format, bool) {
+	for _, f := range formats {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// ForFile picks the format for filename by its extension, defaulting to txt
+func ForFile(filename string) Format {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, f := range formats {
+		if f.Ext != "" && f.Ext == ext {
+			return f
+		}
+	}
+	return formats[0]
+}
+
+// Formats lists the format names, sorted
+func Formats() []string {
+	var names []string
+	for _, f := range formats {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseRange reads a chapter range: "3" (chapter 3 only), "3-7" or "3-"
+// (chapter 3 to the end)
+func ParseRange(s string) (first, last int, err error) {
+	from, to, isRange := strings.Cut(s, "-")
+	if _, err := fmt.Sscan(from, &first); err != nil || first < 1 {
+		return 0, 0, fmt.Errorf("invalid chapter range %q", s)
+	}
+	if !isRange {
+		return first, first, nil
+	}
+	if to == "" {
+		return first, 0, nil
+	}
+	if _, err := fmt.Sscan(to, &last); err != nil || last < first {
+		return 0, 0, fmt.Errorf("invalid chapter range %q", s)
+	}
+	return first, last, nil
+}
+
+// Chapters returns the indexes of the chapters opt selects from p
+func (opt Options) Chapters(p *project.Project) ([]int, error) {
+	first, last := opt.First, opt.Last
+	if first == 0 {
+		first = 1
+	}
+	if last == 0 {
+		last = len(p.Chapters)
+	}
+	if first > len(p.Chapters) || last > len(p.Chapters) || first > last {
+		return nil, fmt.Errorf("chapters %d-%d out of range (project has %d)", first, last, len(p.Chapters))
+	}
+
+	var idx []int
+	for i := first - 1; i < last; i++ {
+		idx = append(idx, i)
+	}
+	return idx, nil
+}
+
+// validate checks options that came from the user
+func (opt Options) validate() error {
+	switch opt.Notes {
+	case NotesNone, NotesQuote, NotesFootnote:
+		return nil
+	}
+	return fmt.Errorf("unknown notes style %q (use %s or %s)", opt.Notes, NotesQuote, NotesFootnote)
+}
+
+// Text writes the manuscript as plain text with a "# Chapter N: Title"
+// header per chapter. Notes and the Wiki are left out.
+func Text(w io.Writer, p *project.Project, opt Options) error {
+	idx, err := opt.Chapters(p)
+	if err != nil {
+		return err
+	}
+	for _, i := range idx {
+		chap := p.Chapters[i]
+		if _, err := fmt.Fprintf(w, "# Chapter %d: %s\n\n%s\n\n", i+1, chap.Title, chap.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// File exports p in format f, adding the format's extension when filename
+// has none, and returns the path it wrote. Directory formats write into
+// filename, creating it if needed.
+func File(filename string, f Format, p *project.Project, opt Options) (string, error) {
+	if err := opt.validate(); err != nil {
+		return "", err
+	}
+	if f.WriteDir != nil {
+		return filename, f.WriteDir(filename, p, opt)
+	}
+	if !strings.Contains(filepath.Base(filename), ".") {
+		filename += f.Ext
+	}
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf, p, opt); err != nil {
+		return "", err
+	}
+	return filename, os.WriteFile(filename, buf.Bytes(), 0644)
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gowrite/project"
+)
+
+func TestText(t *testing.T) {
+	p := project.New()
+	p.Chapters[0].Content = "Once upon a time."
+	p.Chapters[0].Notes = "secret"
+	p.AddChapter("Two")
+
+	var buf bytes.Buffer
+	if err := Text(&buf, p, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "# Chapter 1: The Beginning\n\nOnce upon a time.\n\n# Chapter 2: Two\n\n\n\n"
+	if buf.String() != want {
+		t.Errorf("Text() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFile_AddsExtension(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "book")
+	written, err := File(name, ForFile(name), project.New(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != name+".txt" {
+		t.Errorf("File() wrote %q, want %q", written, name+".txt")
+	}
+	if _, err := os.Stat(written); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		in          string
+		first, last int
+		ok          bool
+	}{
+		{"3", 3, 3, true},
+		{"3-7", 3, 7, true},
+		{"3-", 3, 0, true},
+		{"7-3", 0, 0, false},
+		{"0", 0, 0, false},
+		{"x-2", 0, 0, false},
+	}
+	for _, tt := range tests {
+		first, last, err := ParseRange(tt.in)
+		if (err == nil) != tt.ok || first != tt.first || last != tt.last {
+			t.Errorf("ParseRange(%q) = %d, %d, %v", tt.in, first, last, err)
+		}
+	}
+}
+
+func TestOptions_Chapters(t *testing.T) {
+	p := project.New()
+	p.AddChapter("Two")
+	p.AddChapter("Three")
+
+	idx, err := Options{First: 2}.Chapters(p)
+	if err != nil || len(idx) != 2 || idx[0] != 1 {
+		t.Errorf("Chapters(2-) = %v, %v", idx, err)
+	}
+	if _, err := (Options{First: 2, Last: 5}).Chapters(p); err == nil {
+		t.Error("Chapters(2-5) of 3 chapters: no error")
+	}
+}
+
+func TestForFile(t *testing.T) {
+	for name, want := range map[string]string{"book.md": "md", "book.MD": "md", "book.txt": "txt", "book": "txt"} {
+		if got := ForFile(name).Name; got != want {
+			t.Errorf("ForFile(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
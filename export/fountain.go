@@ -0,0 +1,209 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gowrite/fountain"
+	"gowrite/project"
+)
+
+// Fountain writes the project as a Fountain screenplay: a title page from
+// the metadata, then each chapter as a "# section" followed by its text.
+// Notes become [[notes]], which Fountain apps show but do not print.
+func Fountain(w io.Writer, p *project.Project, opt Options) error {
+	idx, err := opt.Chapters(p)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Title: %s\n", p.Name())
+	if p.Metadata.Author != "" {
+		fmt.Fprintf(&b, "Credit: Written by\nAuthor: %s\n", p.Metadata.Author)
+	}
+	if contact := strings.TrimSpace(p.Metadata.Contact); contact != "" {
+		b.WriteString("Contact:\n" + prefixLines(contact, "    ", "") + "\n")
+	}
+	b.WriteString("\n")
+
+	for _, i := range idx {
+		chap := p.Chapters[i]
+		title := strings.TrimSpace(chap.Title)
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+		fmt.Fprintf(&b, "# %s\n\n", title)
+		if notes := strings.TrimSpace(chap.Notes); opt.Notes != NotesNone && notes != "" {
+			fmt.Fprintf(&b, "[[%s]]\n\n", notes)
+		}
+		if content := strings.TrimSpace(chap.Content); content != "" {
+			b.WriteString(content + "\n\n")
+		}
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// Screenplay page geometry: US Letter, 12pt Courier at six lines to the
+// inch, a 1.5 inch left margin and 1 inch elsewhere
+const (
+	scriptChar   = 7.2 // width of a Courier character at 12pt
+	scriptIndent = 36  // the extra half inch of the left margin
+)
+
+// screenplayPDF typesets a screenplay project in the standard format: a
+// title page, then the scenes running on from page to page with each
+// element at its usual indent and page numbers top right
+func screenplayPDF(w io.Writer, p *project.Project, opt Options) error {
+	if opt.Font != "" && !strings.EqualFold(opt.Font, "courier") {
+		return fmt.Errorf("screenplays are set in courier, not %q", opt.Font)
+	}
+	if opt.Trim == "" {
+		opt.Trim = "letter"
+	}
+	opt.Font, opt.FontSize, opt.Margin = "courier", 12, 1
+	l, err := newPDFLayout(opt)
+	if err != nil {
+		return err
+	}
+	l.leading = 12
+	l.headers, l.numbers, l.scriptNumbers = false, false, true
+	idx, err := opt.Chapters(p)
+	if err != nil {
+		return err
+	}
+
+	// Title page
+	column := func(text string, align int) pdfBlock {
+		return pdfBlock{Spans: []span{{Text: text}}, Align: align, Left: scriptIndent, Width: 60 * scriptChar}
+	}
+	title := column(strings.ToUpper(p.Name()), alignCenter)
+	title.Break, title.Drop = true, 0.3
+	l.add(title)
+	if p.Metadata.Author != "" {
+		by := column("Written by", alignCenter)
+		by.Before = 2
+		author := column(p.Metadata.Author, alignCenter)
+		author.Before = 1
+		l.add(by, author)
+	}
+	if contact := strings.TrimSpace(p.Metadata.Contact); contact != "" {
+		for n, line := range strings.Split(contact, "\n") {
+			b := column(strings.TrimSpace(line), alignLeft)
+			if n == 0 {
+				b.Drop = 0.85
+			}
+			l.add(b)
+		}
+	}
+
+	// The script runs on across chapters
+	var blocks []pdfBlock
+	var prev fountain.Element
+	newPage := true
+	for _, i := range idx {
+		for _, e := range fountain.Parse(p.Chapters[i].Content) {
+			if e.Type == fountain.PageBreak {
+				newPage = true
+				continue
+			}
+			layout, ok := fountain.Layouts[e.Type]
+			if !ok {
+				continue
+			}
+			text := e.Text
+			if layout.Upper {
+				text = strings.ToUpper(text)
+			}
+			align := alignLeft
+			switch {
+			case layout.Right:
+				align = alignRight
+			case layout.Center:
+				align = alignCenter
+			}
+			for n, line := range strings.Split(text, "\n") {
+				b := pdfBlock{
+					Spans: spans(line), Align: align,
+					Left:  scriptIndent + float64(layout.Indent)*scriptChar,
+					Width: float64(layout.Width) * scriptChar,
+					// Keep headings and cues with what follows them
+					KeepNext: e.Type == fountain.SceneHeading || e.Type == fountain.Character,
+				}
+				if n == 0 && !e.Joined(prev) {
+					b.Before = 1
+				}
+				b.Break, newPage = newPage, false
+				blocks = append(blocks, b)
+			}
+			prev = e
+		}
+	}
+	l.add(blocks...)
+
+	d := newPDFDoc(l.width, l.height)
+	l.render(d)
+	return d.write(w, p.Name(), p.Metadata.Author)
+}
+
+// screenplayClasses name the printed element types in HTML
+var screenplayClasses = []struct {
+	Type  fountain.Type
+	Class string
+}{
+	{fountain.Action, "action"},
+	{fountain.SceneHeading, "scene-heading"},
+	{fountain.Character, "character"},
+	{fountain.Parenthetical, "parenthetical"},
+	{fountain.Dialogue, "dialogue"},
+	{fountain.Transition, "transition"},
+	{fountain.Centered, "centered"},
+}
+
+// screenplayCSS sets each element at its indent in Courier, measured in
+// characters so the page matches the printed script
+func screenplayCSS() string {
+	var b strings.Builder
+	b.WriteString(`.screenplay main { font-family: "Courier New", Courier, monospace; max-width: 62ch; }
+.screenplay main p { text-indent: 0; margin: 1.2em 0 0; }
+`)
+	for _, c := range screenplayClasses {
+		layout := fountain.Layouts[c.Type]
+		fmt.Fprintf(&b, ".screenplay .%s { margin-left: %dch; max-width: %dch;", c.Class, layout.Indent, layout.Width)
+		if layout.Upper {
+			b.WriteString(" text-transform: uppercase;")
+		}
+		switch {
+		case layout.Right:
+			b.WriteString(" text-align: right;")
+		case layout.Center:
+			b.WriteString(" text-align: center;")
+		}
+		b.WriteString(" }\n")
+	}
+	b.WriteString(".screenplay main .parenthetical, .screenplay main .dialogue { margin-top: 0; }\n")
+	return b.String()
+}
+
+// screenplayHTML renders a Fountain script as paragraphs classed by element
+func screenplayHTML(text string) string {
+	class := make(map[fountain.Type]string)
+	for _, c := range screenplayClasses {
+		class[c.Type] = c.Class
+	}
+
+	var b strings.Builder
+	for _, e := range fountain.Parse(text) {
+		if !e.Printed() {
+			continue
+		}
+		var lines []string
+		for _, line := range strings.Split(e.Text, "\n") {
+			lines = append(lines, inlineHTML(line))
+		}
+		fmt.Fprintf(&b, "<p class=\"%s\">%s</p>\n", class[e.Type], strings.Join(lines, "<br/>"))
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gowrite/project"
+)
+
+func screenplayProject() *project.Project {
+	p := project.New()
+	p.Mode = project.ModeScreenplay
+	p.Metadata = project.Metadata{Title: "Night Shift", Author: "Jane Doe", Contact: "Jane Doe\njane@example.com"}
+	p.Chapters[0].Title = "Cold Open"
+	p.Chapters[0].Notes = "Keep it short."
+	p.Chapters[0].Content = "INT. DINER - NIGHT\n\nRain on the window.\n\nBRICK\n(quietly)\nCoffee.\n\nCUT TO:"
+	return p
+}
+
+func TestFountain(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Fountain(&buf, screenplayProject(), Options{Notes: NotesQuote}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Title: Night Shift\nCredit: Written by\nAuthor: Jane Doe\nContact:\n    Jane Doe\n    jane@example.com\n\n" +
+		"# Cold Open\n\n[[Keep it short.]]\n\nINT. DINER - NIGHT\n\nRain on the window.\n\nBRICK\n(quietly)\nCoffee.\n\nCUT TO:\n\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestPDF_Screenplay(t *testing.T) {
+	p := screenplayProject()
+	p.AddChapter("More")
+	p.Chapters[1].Content = strings.Repeat("EXT. STREET - DAY\n\nHe walks.\n\n", 40)
+
+	var buf bytes.Buffer
+	if err := PDF(&buf, p, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/MediaBox [0 0 612.00 792.00]")) || !bytes.Contains(buf.Bytes(), []byte("/BaseFont /Courier")) {
+		t.Error("a screenplay is Courier on US Letter")
+	}
+	pages := readPDF(t, buf.Bytes())
+	if len(pages) < 3 {
+		t.Fatalf("got %d pages", len(pages))
+	}
+	if !strings.Contains(pages[0], "(NIGHT SHIFT)") || !strings.Contains(pages[0], "(Written by)") {
+		t.Error("title page")
+	}
+
+	// Each element sits at its indent from the 1.5 inch margin
+	for _, want := range []string{
+		"1 0 0 1 108.00 708.00 Tm", // INT. DINER - NIGHT, top line of the page
+		"1 0 0 1 266.40 ",          // BRICK, 2.2 inches further in
+		"1 0 0 1 223.20 ",          // (quietly)
+		"1 0 0 1 180.00 ",          // Coffee.
+	} {
+		if !strings.Contains(pages[1], want) {
+			t.Errorf("page 1 of the script lacks %q:\n%s", want, pages[1])
+		}
+	}
+	if strings.Contains(pages[1], "(1.)") || !strings.Contains(pages[2], "(2.)") {
+		t.Error("script pages are numbered from the second")
+	}
+	if strings.Contains(pages[1], "Cold Open") {
+		t.Error("section headings are not printed")
+	}
+
+	if err := PDF(&buf, p, Options{Font: "times"}); err == nil {
+		t.Error("screenplay in times accepted")
+	}
+}
+
+func TestScreenplayHTML(t *testing.T) {
+	got := screenplayHTML("# Act One\n\nBRICK\nCoffee, *now*.\nPlease.")
+	want := "<p class=\"character\">BRICK</p>\n<p class=\"dialogue\">Coffee, <em>now</em>.<br/>Please.</p>\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"html"
+	"strings"
+)
+
+// inlineHTML escapes a line of prose and turns *emphasis* and **strong**
+// text into tags
+func inlineHTML(line string) string {
+	var b strings.Builder
+	for _, s := range spans(line) {
+		text := html.EscapeString(s.Text)
+		switch {
+		case s.Bold:
+			b.WriteString("<strong>" + text + "</strong>")
+		case s.Italic:
+			b.WriteString("<em>" + text + "</em>")
+		default:
+			b.WriteString(text)
+		}
+	}
+	return b.String()
+}
+
+// proseHTML renders chapter text as XHTML: every line is a paragraph, blank
+// lines are ignored and scene break lines (*** or #) become <hr/>
+func proseHTML(text string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case isSceneBreak(line):
+			b.WriteString("<hr class=\"scene-break\"/>\n")
+		default:
+			b.WriteString("<p>" + inlineHTML(line) + "</p>\n")
+		}
+	}
+	return b.String()
+}
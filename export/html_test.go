@@ -0,0 +1,24 @@
+package export
+
+import "testing"
+
+func TestInlineHTML(t *testing.T) {
+	tests := map[string]string{
+		"plain & simple":           "plain &amp; simple",
+		"*very* **bold** <b>":      "<em>very</em> <strong>bold</strong> &lt;b&gt;",
+		"_quite_ so, snake_case_x": "<em>quite</em> so, snake_case_x",
+	}
+	for in, want := range tests {
+		if got := inlineHTML(in); got != want {
+			t.Errorf("inlineHTML(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestProseHTML(t *testing.T) {
+	got := proseHTML("First line.\n\n  * * *  \nSecond.")
+	want := "<p>First line.</p>\n<hr class=\"scene-break\"/>\n<p>Second.</p>\n"
+	if got != want {
+		t.Errorf("proseHTML() = %q, want %q", got, want)
+	}
+}
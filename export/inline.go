@@ -0,0 +1,58 @@
+package export
+
+import (
+	"regexp"
+	"strings"
+)
+
+// span is a run of prose text with the same emphasis
+type span struct {
+	Text   string
+	Italic bool
+	Bold   bool
+}
+
+// emphasisRegex finds **strong**, *emphasis* and _emphasis_ (only at word
+// boundaries, so snake_case stays as it is)
+var emphasisRegex = regexp.MustCompile(`\*\*([^*]+)\*\*|\*([^*]+)\*|(?:^|[^\pL\pN_])(_([^_]+)_)(?:$|[^\pL\pN_])`)
+
+// spans splits a line of prose at its inline Markdown emphasis
+func spans(line string) []span {
+	var out []span
+	plain := func(s string) {
+		if s != "" {
+			out = append(out, span{Text: s})
+		}
+	}
+	for line != "" {
+		m := emphasisRegex.FindStringSubmatchIndex(line)
+		if m == nil {
+			break
+		}
+		switch {
+		case m[2] >= 0: // **strong**
+			plain(line[:m[0]])
+			out = append(out, span{Text: line[m[2]:m[3]], Bold: true})
+			line = line[m[1]:]
+		case m[4] >= 0: // *emphasis*
+			plain(line[:m[0]])
+			out = append(out, span{Text: line[m[4]:m[5]], Italic: true})
+			line = line[m[1]:]
+		default: // _emphasis_, without the boundary characters around it
+			plain(line[:m[6]])
+			out = append(out, span{Text: line[m[8]:m[9]], Italic: true})
+			line = line[m[7]:]
+		}
+	}
+	plain(line)
+	return out
+}
+
+// isSceneBreak reports whether a line only separates scenes
+func isSceneBreak(line string) bool {
+	switch strings.ReplaceAll(line, " ", "") {
+	case "***", "---", "#", "###":
+		return true
+	}
+	return false
+}
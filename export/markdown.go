@@ -0,0 +1,78 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gowrite/project"
+)
+
+// Markdown writes the manuscript with a "# Title" heading per chapter.
+// Depending on opt it starts with YAML front matter, carries each chapter's
+// notes as a blockquote or a footnote, and ends with the Story Wiki as an
+// appendix.
+func Markdown(w io.Writer, p *project.Project, opt Options) error {
+	idx, err := opt.Chapters(p)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+
+	if opt.FrontMatter {
+		fmt.Fprintf(bw, "---\ntitle: %s\n", strconv.Quote(p.Name()))
+		if p.Metadata.Author != "" {
+			fmt.Fprintf(bw, "author: %s\n", strconv.Quote(p.Metadata.Author))
+		}
+		fmt.Fprint(bw, "---\n\n")
+	}
+
+	for _, i := range idx {
+		chap := p.Chapters[i]
+		notes := strings.TrimSpace(chap.Notes)
+		footnote := fmt.Sprintf("[^notes-%d]", i+1)
+
+		fmt.Fprintf(bw, "# %s", chap.Title)
+		if notes != "" && opt.Notes == NotesFootnote {
+			fmt.Fprint(bw, footnote)
+		}
+		fmt.Fprint(bw, "\n\n")
+
+		if notes != "" && opt.Notes == NotesQuote {
+			fmt.Fprintf(bw, "%s\n\n", prefixLines(notes, "> ", ">"))
+		}
+		if content := strings.TrimSpace(chap.Content); content != "" {
+			fmt.Fprintf(bw, "%s\n\n", content)
+		}
+		if notes != "" && opt.Notes == NotesFootnote {
+			// Footnote paragraphs after the first are indented to belong to it
+			fmt.Fprintf(bw, "%s: %s\n\n", footnote, strings.TrimPrefix(prefixLines(notes, "    ", ""), "    "))
+		}
+	}
+
+	if opt.Wiki {
+		fmt.Fprint(bw, "# Appendix: Story Wiki\n\n")
+		for _, entry := range p.Wiki {
+			fmt.Fprintf(bw, "## %s\n\n", entry.Title)
+			if content := strings.TrimSpace(entry.Content); content != "" {
+				fmt.Fprintf(bw, "%s\n\n", content)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// prefixLines puts prefix before every line of text, or blank for empty lines
+func prefixLines(text, prefix, blank string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = blank
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
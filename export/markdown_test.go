@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"gowrite/project"
+)
+
+func markdownProject() *project.Project {
+	p := project.New()
+	p.Metadata = project.Metadata{Title: "The Call", Author: "Jane Doe"}
+	p.Chapters[0].Content = "The phone rang.\n"
+	p.Chapters[0].Notes = "Foreshadow.\n\nMore."
+	p.AddChapter("Two")
+	p.Chapters[1].Content = "Silence."
+	p.Wiki[0].Content = "Victorian London"
+	return p
+}
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Options
+		want string
+	}{
+		{"plain", Options{},
+			"# The Beginning\n\nThe phone rang.\n\n# Two\n\nSilence.\n\n"},
+		{"front matter and range", Options{FrontMatter: true, First: 2},
+			"---\ntitle: \"The Call\"\nauthor: \"Jane Doe\"\n---\n\n# Two\n\nSilence.\n\n"},
+		{"notes as quote", Options{Notes: NotesQuote, Last: 1},
+			"# The Beginning\n\n> Foreshadow.\n>\n> More.\n\nThe phone rang.\n\n"},
+		{"notes as footnote", Options{Notes: NotesFootnote, Last: 1},
+			"# The Beginning[^notes-1]\n\nThe phone rang.\n\n[^notes-1]: Foreshadow.\n\n    More.\n\n"},
+		{"wiki appendix", Options{Wiki: true, First: 2},
+			"# Two\n\nSilence.\n\n# Appendix: Story Wiki\n\n## General Notes\n\nVictorian London\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Markdown(&buf, markdownProject(), tt.opt); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Markdown() =\n%q\nwant\n%q", buf.String(), tt.want)
+			}
+		})
+	}
+}
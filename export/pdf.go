@@ -0,0 +1,406 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gowrite/project"
+)
+
+// trimSizes are the page sizes --trim accepts by name, in points. Any
+// "<width>x<height>" in inches works too.
+var trimSizes = map[string][2]float64{
+	"letter": {612, 792},
+	"a4":     {595.28, 841.89},
+	"a5":     {419.53, 595.28},
+	"6x9":    {432, 648},
+}
+
+// ParseTrim reads a page size: a name from trimSizes or "WxH" in inches,
+// e.g. "5.5x8.5". The default is 6x9, the common trade paperback size.
+func ParseTrim(s string) (width, height float64, err error) {
+	if s == "" {
+		s = "6x9"
+	}
+	if size, ok := trimSizes[strings.ToLower(s)]; ok {
+		return size[0], size[1], nil
+	}
+	w, h, ok := strings.Cut(strings.ToLower(s), "x")
+	if ok {
+		wi, err1 := strconv.ParseFloat(w, 64)
+		hi, err2 := strconv.ParseFloat(h, 64)
+		if err1 == nil && err2 == nil && wi >= 2 && hi >= 2 && wi <= 20 && hi <= 20 {
+			return wi * 72, hi * 72, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid trim size %q (use letter, a4, a5 or e.g. 6x9 in inches)", s)
+}
+
+// Horizontal alignment of a block
+const (
+	alignJustify = iota
+	alignLeft
+	alignCenter
+	alignRight
+)
+
+// pdfBlock is a paragraph to typeset
+type pdfBlock struct {
+	Spans    []span
+	Size     float64 // font size as a multiple of the body size; 0 means 1
+	Align    int
+	Indent   float64 // first line indent, points
+	Left     float64 // inset from the left margin, points
+	Width    float64 // measure, points; 0 runs to the right margin
+	Before   float64 // space above, in lines; dropped at the top of a page
+	KeepNext bool    // move to the next page rather than end a page (headings)
+
+	Break   bool    // start a new page
+	Drop    float64 // start at least this fraction of the text height down the page
+	Chapter string  // with Break: running header from here on; no header on this page
+}
+
+// pdfFrag is a run of text in one font on a line
+type pdfFrag struct {
+	font pdfFont
+	size float64
+	text string
+}
+
+// pdfLine is a line placed on a page
+type pdfLine struct {
+	x, baseline float64
+	spacing     float64 // extra width of each space, to justify
+	frags       []pdfFrag
+}
+
+// pdfPage is a page of placed lines
+type pdfPage struct {
+	lines   []pdfLine
+	header  string // running header, chapter title
+	opening bool   // first page of a chapter: no running header
+}
+
+// pdfLayout typesets blocks into pages
+type pdfLayout struct {
+	family        pdfFamily
+	size, leading float64 // body font size and line height, points
+	width, height float64 // page size
+	margin        float64
+	recto         bool   // chapters open on right-hand (odd) pages
+	headers       bool   // running headers: verso on even pages, chapter title on odd
+	numbers       bool   // page numbers, except on the title page and blank pages
+	scriptNumbers bool   // screenplay style instead: "2." top right, from the second page after the title
+	verso         string // left-hand running header
+	ragged        bool   // never justify
+	pages         []*pdfPage
+	y             float64 // distance below the top margin
+	chapter       string
+}
+
+// newPDFLayout sets up the page from opt
+func newPDFLayout(opt Options) (*pdfLayout, error) {
+	family, err := pdfFamilyNamed(opt.Font)
+	if err != nil {
+		return nil, err
+	}
+	width, height, err := ParseTrim(opt.Trim)
+	if err != nil {
+		return nil, err
+	}
+	size := opt.FontSize
+	if size == 0 {
+		size = 11
+	}
+	margin := opt.Margin * 72
+	if margin == 0 {
+		margin = 54 // three quarters of an inch
+	}
+	if size < 4 || size > 72 || 2*margin >= width-72 || 2*margin >= height-72 {
+		return nil, fmt.Errorf("margins of %.2fin leave no room for %gpt text on a %.2fx%.2fin page", margin/72, size, width/72, height/72)
+	}
+	return &pdfLayout{
+		family: family, size: size, leading: size * 1.35,
+		width: width, height: height, margin: margin,
+		recto: opt.Recto, headers: !opt.NoHeaders, numbers: !opt.NoPageNumbers,
+		ragged: family.Regular.Name == "Courier",
+	}, nil
+}
+
+// textHeight is the height of the text area
+func (l *pdfLayout) textHeight() float64 { return l.height - 2*l.margin }
+
+// textWidth is the width of the text area
+func (l *pdfLayout) textWidth() float64 { return l.width - 2*l.margin }
+
+// newPage starts a page
+func (l *pdfLayout) newPage() *pdfPage {
+	pg := &pdfPage{header: l.chapter}
+	l.pages = append(l.pages, pg)
+	l.y = 0
+	return pg
+}
+
+// page is the page being filled
+func (l *pdfLayout) page() *pdfPage {
+	if len(l.pages) == 0 {
+		return l.newPage()
+	}
+	return l.pages[len(l.pages)-1]
+}
+
+// add typesets blocks onto the pages
+func (l *pdfLayout) add(blocks ...pdfBlock) {
+	for i, b := range blocks {
+		if b.Size == 0 {
+			b.Size = 1
+		}
+		size := l.size * b.Size
+		lh := l.leading * b.Size
+
+		if b.Break {
+			if len(l.pages) == 0 || len(l.page().lines) > 0 {
+				l.newPage()
+			}
+			if l.recto && len(l.pages)%2 == 0 {
+				l.newPage() // leave the left-hand page blank
+			}
+			if b.Chapter != "" {
+				l.chapter = b.Chapter
+				l.page().header = b.Chapter
+				l.page().opening = true
+			}
+		}
+		if drop := b.Drop * l.textHeight(); drop > l.y {
+			l.y = drop
+		}
+
+		lines := l.breakLines(b, size)
+		if l.y > 0 {
+			l.y += b.Before * l.leading
+		}
+		need := float64(len(lines)) * lh
+		if b.KeepNext && i+1 < len(blocks) {
+			need += 2 * l.leading
+		}
+		if b.KeepNext && l.y > 0 && l.y+need > l.textHeight() {
+			l.newPage()
+		}
+
+		for _, line := range lines {
+			if l.y+lh > l.textHeight()+0.01 {
+				l.newPage()
+			}
+			pg := l.page()
+			line.baseline = l.height - l.margin - l.y - size
+			pg.lines = append(pg.lines, line)
+			l.y += lh
+		}
+	}
+}
+
+// pdfWord is text between spaces, possibly in several fonts
+type pdfWord struct {
+	frags []pdfFrag
+	width float64
+}
+
+// breakLines fills lines greedily and aligns them
+func (l *pdfLayout) breakLines(b pdfBlock, size float64) []pdfLine {
+	var words []pdfWord
+	var cur pdfWord
+	flush := func() {
+		if len(cur.frags) > 0 {
+			words = append(words, cur)
+			cur = pdfWord{}
+		}
+	}
+	for _, s := range b.Spans {
+		font := l.family.style(s.Italic, s.Bold)
+		for i, piece := range strings.Split(s.Text, " ") {
+			if i > 0 {
+				flush()
+			}
+			if piece != "" {
+				cur.frags = append(cur.frags, pdfFrag{font: font, size: size, text: piece})
+				cur.width += font.width(piece, size)
+			}
+		}
+	}
+	flush()
+
+	measure := b.Width
+	if measure == 0 {
+		measure = l.textWidth() - b.Left
+	}
+	space := l.family.Regular.width(" ", size)
+	left := l.margin + b.Left
+
+	var lines []pdfLine
+	for start := 0; start < len(words) || (start == 0 && len(lines) == 0); {
+		indent := 0.0
+		if len(lines) == 0 {
+			indent = b.Indent
+		}
+		end, width := start, 0.0
+		for end < len(words) {
+			w := words[end].width
+			if end > start {
+				w += space
+			}
+			if end > start && width+w > measure-indent {
+				break
+			}
+			width += w
+			end++
+		}
+
+		line := pdfLine{x: left + indent}
+		for i := start; i < end; i++ {
+			for j, f := range words[i].frags {
+				if j == 0 && i > start {
+					f.text = " " + f.text
+				}
+				// Runs in the same font are drawn together
+				if n := len(line.frags); n > 0 && line.frags[n-1].font.Name == f.font.Name && line.frags[n-1].size == f.size {
+					line.frags[n-1].text += f.text
+				} else {
+					line.frags = append(line.frags, f)
+				}
+			}
+		}
+		free := measure - indent - width
+		switch b.Align {
+		case alignCenter:
+			line.x += free / 2
+		case alignRight:
+			line.x += free
+		case alignJustify:
+			if end < len(words) && end-start > 1 && !l.ragged {
+				line.spacing = free / float64(end-start-1)
+			}
+		}
+		lines = append(lines, line)
+		if end == start {
+			break // an empty block still takes a line
+		}
+		start = end
+	}
+	return lines
+}
+
+// render draws the pages with their running headers and page numbers
+func (l *pdfLayout) render(d *pdfDoc) {
+	small := l.size * 0.85
+	headerFont := l.family.Italic
+	for i, pg := range l.pages {
+		var c bytes.Buffer
+		for _, line := range pg.lines {
+			fmt.Fprintf(&c, "BT %.3f Tw 1 0 0 1 %.2f %.2f Tm", line.spacing, line.x, line.baseline)
+			for _, f := range line.frags {
+				fmt.Fprintf(&c, " /%s %.2f Tf %s Tj", d.font(f.font), f.size, pdfString(f.font.encode(f.text)))
+			}
+			c.WriteString(" ET\n")
+		}
+
+		number := i + 1
+		blank := len(pg.lines) == 0
+		if l.headers && i > 0 && !pg.opening && !blank {
+			header := pg.header
+			if number%2 == 0 && l.verso != "" {
+				header = l.verso
+			}
+			if header != "" {
+				x := (l.width - headerFont.width(header, small)) / 2
+				fmt.Fprintf(&c, "BT 0 Tw 1 0 0 1 %.2f %.2f Tm /%s %.2f Tf %s Tj ET\n", x, l.height-l.margin/2-small/2, d.font(headerFont), small, pdfString(headerFont.encode(header)))
+			}
+		}
+		if l.scriptNumbers && i > 1 {
+			n := strconv.Itoa(i) + "."
+			x := l.width - l.margin - l.family.Regular.width(n, l.size)
+			fmt.Fprintf(&c, "BT 0 Tw 1 0 0 1 %.2f %.2f Tm /%s %.2f Tf %s Tj ET\n", x, l.height-l.margin/2-l.size/2, d.font(l.family.Regular), l.size, pdfString([]byte(n)))
+		} else if l.numbers && i > 0 && !blank {
+			n := strconv.Itoa(number)
+			x := (l.width - l.family.Regular.width(n, small)) / 2
+			fmt.Fprintf(&c, "BT 0 Tw 1 0 0 1 %.2f %.2f Tm /%s %.2f Tf %s Tj ET\n", x, l.margin/2-small/2, d.font(l.family.Regular), small, pdfString([]byte(n)))
+		}
+		d.addPage(c.Bytes())
+	}
+}
+
+// PDF typesets the manuscript as a book proof: a title page, each chapter
+// opening on a new page (a right-hand one with --recto) a third of the way
+// down, running headers and page numbers. Trim size, margins, font and size
+// come from opt.
+func PDF(w io.Writer, p *project.Project, opt Options) error {
+	if p.Mode == project.ModeScreenplay {
+		return screenplayPDF(w, p, opt)
+	}
+	l, err := newPDFLayout(opt)
+	if err != nil {
+		return err
+	}
+	idx, err := opt.Chapters(p)
+	if err != nil {
+		return err
+	}
+	l.verso = p.Metadata.Author
+	if l.verso == "" {
+		l.verso = p.Name()
+	}
+
+	// Title page
+	l.add(pdfBlock{Spans: []span{{Text: p.Name()}}, Size: 2, Align: alignCenter, Break: true, Drop: 0.3})
+	if p.Metadata.Author != "" {
+		l.add(pdfBlock{Spans: []span{{Text: p.Metadata.Author}}, Size: 1.2, Align: alignCenter, Before: 2})
+	}
+
+	for _, i := range idx {
+		chap := p.Chapters[i]
+		l.add(l.heading(fmt.Sprintf("Chapter %d", i+1), chap.Title, chap.Title)...)
+		l.add(l.prose(chap.Content)...)
+	}
+	if opt.Wiki {
+		l.add(l.heading("Appendix", "Story Wiki", "Story Wiki")...)
+		for _, entry := range p.Wiki {
+			title := pdfBlock{Spans: []span{{Text: entry.Title, Bold: true}}, Align: alignLeft, Before: 1, KeepNext: true}
+			l.add(append([]pdfBlock{title}, l.prose(entry.Content)...)...)
+		}
+	}
+
+	d := newPDFDoc(l.width, l.height)
+	l.render(d)
+	return d.write(w, p.Name(), p.Metadata.Author)
+}
+
+// heading opens a chapter on a new page
+func (l *pdfLayout) heading(label, title, header string) []pdfBlock {
+	blocks := []pdfBlock{{Spans: []span{{Text: label}}, Align: alignCenter, Break: true, Drop: 0.25, Chapter: header}}
+	if title != "" {
+		blocks = append(blocks, pdfBlock{Spans: spans(title), Size: 1.6, Align: alignCenter, Before: 0.5})
+	}
+	return blocks
+}
+
+// prose turns text into paragraphs: indented except the first and those
+// after a scene break
+func (l *pdfLayout) prose(text string) []pdfBlock {
+	var blocks []pdfBlock
+	indent, before := 0.0, 2.0
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case isSceneBreak(line):
+			blocks = append(blocks, pdfBlock{Spans: []span{{Text: "* * *"}}, Align: alignCenter, Before: 0.5})
+			indent, before = 0, 0.5
+		default:
+			blocks = append(blocks, pdfBlock{Spans: spans(line), Indent: indent, Before: before})
+			indent, before = 1.5*l.size, 0
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,182 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// readPDF checks the cross-reference table points at every object and
+// returns the decompressed content stream of each page
+func readPDF(t *testing.T, data []byte) []string {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(data[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, data[off:off+10])
+		}
+	}
+
+	var pages []string
+	for _, s := range regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindAllSubmatchIndex(data, -1) {
+		n, _ := strconv.Atoi(string(data[s[2]:s[3]]))
+		r, err := zlib.NewReader(bytes.NewReader(data[s[1] : s[1]+n]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(r)
+		pages = append(pages, string(content))
+	}
+	if count := regexp.MustCompile(`/Count (\d+)`).FindSubmatch(data); count == nil || string(count[1]) != strconv.Itoa(len(pages)) {
+		t.Errorf("page count %s, %d content streams", count, len(pages))
+	}
+	return pages
+}
+
+func TestPDF(t *testing.T) {
+	p := markdownProject()
+	p.Chapters[1].Content = "It was *very* quiet (too quiet).\n\n***\n\nThe end."
+
+	var buf bytes.Buffer
+	if err := PDF(&buf, p, Options{Wiki: true}); err != nil {
+		t.Fatal(err)
+	}
+	pages := readPDF(t, buf.Bytes())
+	if len(pages) != 4 {
+		t.Fatalf("got %d pages, want title, two chapters and the appendix", len(pages))
+	}
+	for i, want := range []string{"(The Call)", "(The Beginning)", `quiet \(too quiet\).`, "(Story Wiki)"} {
+		if !strings.Contains(pages[i], want) {
+			t.Errorf("page %d lacks %s:\n%s", i+1, want, pages[i])
+		}
+	}
+	if !strings.Contains(pages[1], "(2)") || strings.Contains(pages[0], "(1)") {
+		t.Error("page numbers should start after the title page")
+	}
+	if strings.Contains(pages[1], "(Jane Doe)") {
+		t.Error("running header on a chapter's opening page")
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/BaseFont /Times-Italic")) {
+		t.Error("italic font not used")
+	}
+
+	// Opening on the right leaves the backs of the title page and of
+	// chapter one's single page blank
+	buf.Reset()
+	if err := PDF(&buf, p, Options{Recto: true, NoPageNumbers: true}); err != nil {
+		t.Fatal(err)
+	}
+	pages = readPDF(t, buf.Bytes())
+	if len(pages) != 5 || pages[1] != "" || pages[3] != "" || !strings.Contains(pages[4], "(Two)") {
+		t.Errorf("recto layout: %q", pages)
+	}
+
+	// A long chapter runs on with running headers: the author on left-hand
+	// pages, the chapter title on right-hand ones
+	p.Chapters[0].Content = strings.Repeat("All work and no play makes Jack a dull boy.\n", 200)
+	buf.Reset()
+	if err := PDF(&buf, p, Options{Last: 1}); err != nil {
+		t.Fatal(err)
+	}
+	pages = readPDF(t, buf.Bytes())
+	if len(pages) < 4 || !strings.Contains(pages[2], "(The Beginning)") || !strings.Contains(pages[3], "(Jane Doe)") {
+		t.Errorf("running headers missing on pages 3 and 4 of %d", len(pages))
+	}
+
+	if err := PDF(&buf, p, Options{Font: "comic"}); err == nil {
+		t.Error("unknown font accepted")
+	}
+}
+
+func TestParseTrim(t *testing.T) {
+	tests := []struct {
+		in   string
+		w, h float64
+		err  bool
+	}{
+		{"", 432, 648, false},
+		{"A4", 595.28, 841.89, false},
+		{"5.5x8.5", 396, 612, false},
+		{"5x", 0, 0, true},
+		{"1x1", 0, 0, true},
+		{"folio", 0, 0, true},
+	}
+	for _, tt := range tests {
+		w, h, err := ParseTrim(tt.in)
+		if (err != nil) != tt.err || w != tt.w || h != tt.h {
+			t.Errorf("ParseTrim(%q) = %v, %v, %v", tt.in, w, h, err)
+		}
+	}
+}
+
+func TestPDFLayout_Lines(t *testing.T) {
+	l, err := newPDFLayout(Options{Trim: "letter", Margin: 1, FontSize: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := strings.Repeat("All work and no play makes Jack a dull boy. ", 20)
+	lines := l.breakLines(pdfBlock{Spans: spans(text), Indent: 18}, 12)
+	if len(lines) < 2 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	for i, line := range lines {
+		width := 0.0
+		spaces := 0
+		for _, f := range line.frags {
+			width += f.font.width(f.text, f.size)
+			spaces += strings.Count(f.text, " ")
+		}
+		width += line.spacing * float64(spaces)
+		if right := line.x + width; right > l.width-l.margin+0.01 {
+			t.Errorf("line %d runs to %.2f, past the margin", i, right)
+		}
+		if i < len(lines)-1 && l.width-l.margin-(line.x+width) > 0.01 {
+			t.Errorf("line %d is not justified: ends at %.2f", i, line.x+width)
+		}
+	}
+	if lines[0].x != l.margin+18 || lines[1].x != l.margin {
+		t.Error("first line indent")
+	}
+	if last := lines[len(lines)-1]; last.spacing != 0 {
+		t.Error("last line justified")
+	}
+
+	if _, err := newPDFLayout(Options{Trim: "a5", Margin: 3}); err == nil {
+		t.Error("margins wider than the page accepted")
+	}
+}
+
+func TestPDFFonts_Widths(t *testing.T) {
+	for name, family := range pdfFamilies {
+		for _, f := range []pdfFont{family.Regular, family.Italic, family.Bold, family.BoldItalic} {
+			if len(f.ascii) != 1 && len(f.ascii) != '~'-' '+1 {
+				t.Errorf("%s %s: %d widths", name, f.Name, len(f.ascii))
+			}
+		}
+	}
+	// Bold italic has metrics of its own: a narrower capital W than bold
+	times := pdfFamilies["times"]
+	if w := times.BoldItalic.width("W", 1000); w != 889 {
+		t.Errorf("Times-BoldItalic W = %v, want 889", w)
+	}
+	if w := times.Bold.width("W", 1000); w != 1000 {
+		t.Errorf("Times-Bold W = %v, want 1000", w)
+	}
+}
@@ -0,0 +1,181 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pdfFont is one of the standard PDF fonts every viewer has built in, so
+// nothing needs embedding. Widths are in thousandths of the font size.
+type pdfFont struct {
+	Name   string // PostScript name, e.g. "Times-Italic"
+	ascii  []int  // advance widths of ' ' (32) to '~' (126)
+	quotes [4]int // ‘ ’ “ ”
+	dashes [2]int // – —
+	dots   int    // …
+}
+
+// Widths of the printable ASCII characters, from the Adobe font metrics
+var (
+	timesRoman = []int{
+		250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 278, 278, 564, 564, 564, 444,
+		921, 722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889, 722, 722,
+		556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611, 333, 278, 333, 469, 500,
+		333, 444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778, 500, 500,
+		500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444, 480, 200, 480, 541,
+	}
+	timesItalic = []int{
+		250, 333, 420, 500, 500, 833, 778, 214, 333, 333, 500, 675, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 675, 675, 675, 500,
+		920, 611, 611, 667, 722, 611, 611, 722, 722, 333, 444, 667, 556, 833, 667, 722,
+		611, 722, 611, 500, 556, 722, 611, 833, 611, 556, 556, 389, 278, 389, 422, 500,
+		333, 500, 500, 444, 500, 444, 278, 500, 500, 278, 278, 444, 278, 722, 500, 500,
+		500, 500, 389, 389, 278, 500, 444, 667, 444, 444, 389, 400, 275, 400, 541,
+	}
+	timesBold = []int{
+		250, 333, 555, 500, 500, 1000, 833, 278, 333, 333, 500, 570, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 570, 570, 570, 500,
+		930, 722, 667, 722, 722, 667, 611, 778, 778, 389, 500, 778, 667, 944, 722, 778,
+		611, 778, 722, 556, 667, 722, 722, 1000, 722, 722, 667, 333, 278, 333, 581, 500,
+		333, 500, 556, 444, 556, 444, 333, 500, 556, 278, 333, 556, 278, 833, 556, 500,
+		556, 556, 444, 389, 333, 556, 500, 722, 500, 500, 444, 394, 220, 394, 520,
+	}
+	timesBoldItalic = []int{
+		250, 389, 555, 500, 500, 833, 778, 278, 333, 333, 500, 570, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 570, 570, 570, 500,
+		832, 667, 667, 667, 722, 667, 667, 722, 778, 389, 500, 667, 611, 889, 722, 722,
+		611, 722, 667, 556, 611, 722, 667, 889, 667, 611, 611, 333, 278, 333, 570, 500,
+		333, 500, 500, 444, 500, 444, 333, 500, 556, 278, 278, 500, 278, 778, 556, 500,
+		500, 500, 389, 389, 278, 556, 444, 667, 500, 444, 389, 348, 220, 348, 570,
+	}
+	helvetica = []int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBold = []int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+	courier = []int{600}
+)
+
+// pdfFamily is a typeface in its four styles
+type pdfFamily struct {
+	Regular, Italic, Bold, BoldItalic pdfFont
+}
+
+// pdfFamilies are the typefaces --font picks from
+var pdfFamilies = map[string]pdfFamily{
+	"times": {
+		Regular:    pdfFont{"Times-Roman", timesRoman, [4]int{333, 333, 444, 444}, [2]int{500, 1000}, 1000},
+		Italic:     pdfFont{"Times-Italic", timesItalic, [4]int{333, 333, 556, 556}, [2]int{500, 889}, 889},
+		Bold:       pdfFont{"Times-Bold", timesBold, [4]int{333, 333, 500, 500}, [2]int{500, 1000}, 1000},
+		BoldItalic: pdfFont{"Times-BoldItalic", timesBoldItalic, [4]int{333, 333, 500, 500}, [2]int{500, 1000}, 1000},
+	},
+	"helvetica": {
+		Regular:    pdfFont{"Helvetica", helvetica, [4]int{222, 222, 333, 333}, [2]int{556, 1000}, 1000},
+		Italic:     pdfFont{"Helvetica-Oblique", helvetica, [4]int{222, 222, 333, 333}, [2]int{556, 1000}, 1000},
+		Bold:       pdfFont{"Helvetica-Bold", helveticaBold, [4]int{278, 278, 500, 500}, [2]int{556, 1000}, 1000},
+		BoldItalic: pdfFont{"Helvetica-BoldOblique", helveticaBold, [4]int{278, 278, 500, 500}, [2]int{556, 1000}, 1000},
+	},
+	"courier": {
+		Regular:    pdfFont{"Courier", courier, [4]int{600, 600, 600, 600}, [2]int{600, 600}, 600},
+		Italic:     pdfFont{"Courier-Oblique", courier, [4]int{600, 600, 600, 600}, [2]int{600, 600}, 600},
+		Bold:       pdfFont{"Courier-Bold", courier, [4]int{600, 600, 600, 600}, [2]int{600, 600}, 600},
+		BoldItalic: pdfFont{"Courier-BoldOblique", courier, [4]int{600, 600, 600, 600}, [2]int{600, 600}, 600},
+	},
+}
+
+// pdfFamilyNamed looks up a --font name; the default is Times
+func pdfFamilyNamed(name string) (pdfFamily, error) {
+	if name == "" {
+		name = "times"
+	}
+	f, ok := pdfFamilies[strings.ToLower(name)]
+	if !ok {
+		return f, fmt.Errorf("unknown font %q for pdf (use times, helvetica or courier)", name)
+	}
+	return f, nil
+}
+
+// style picks the font for a span
+func (f pdfFamily) style(italic, bold bool) pdfFont {
+	switch {
+	case italic && bold:
+		return f.BoldItalic
+	case bold:
+		return f.Bold
+	case italic:
+		return f.Italic
+	}
+	return f.Regular
+}
+
+// winAnsi maps the characters of Windows-1252 outside Latin-1 to their codes
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// latinBase approximates the letters of Latin-1 (0xC0-0xFF) by an ASCII
+// letter of the same width
+const latinBase = "AAAAAAMCEEEEIIIIDNOOOOO+OUUUUYPbaaaaaamceeeeiiiidnooooo+ouuuuypy"
+
+// encode converts text to the font's WinAnsi bytes; characters it cannot
+// show become '?'
+func (f pdfFont) encode(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r >= 32 && r < 127, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		case winAnsi[r] != 0:
+			out = append(out, winAnsi[r])
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// width returns the advance width of text at size points
+func (f pdfFont) width(text string, size float64) float64 {
+	total := 0
+	for _, c := range f.encode(text) {
+		total += f.charWidth(c)
+	}
+	return float64(total) * size / 1000
+}
+
+// charWidth is the width of one encoded character
+func (f pdfFont) charWidth(c byte) int {
+	if len(f.ascii) == 1 {
+		return f.ascii[0] // monospaced
+	}
+	switch {
+	case c >= 32 && c < 127:
+		return f.ascii[c-32]
+	case c >= 0xC0:
+		return f.ascii[latinBase[c-0xC0]-32]
+	case c >= 0x91 && c <= 0x94:
+		return f.quotes[c-0x91]
+	case c == 0x96 || c == 0x97:
+		return f.dashes[c-0x96]
+	case c == 0x85 || c == 0x89 || c == 0x99:
+		return f.dots
+	case c == 0xA0:
+		return f.ascii[0]
+	}
+	return f.ascii['o'-32]
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"unicode/utf16"
+)
+
+// pdfDoc assembles a PDF file object by object. Object 1 is the catalog and
+// object 2 the page tree; pages and fonts are added after.
+type pdfDoc struct {
+	objects [][]byte // body of object i+1
+	pages   []int    // object numbers of the pages
+	fonts   map[string]int
+	width   float64 // page size in points
+	height  float64
+}
+
+func newPDFDoc(width, height float64) *pdfDoc {
+	return &pdfDoc{objects: make([][]byte, 2), fonts: make(map[string]int), width: width, height: height}
+}
+
+// add stores an object and returns its number
+func (d *pdfDoc) add(body string) int {
+	d.objects = append(d.objects, []byte(body))
+	return len(d.objects)
+}
+
+// font returns the resource name of a standard font, e.g. "F1"
+func (d *pdfDoc) font(f pdfFont) string {
+	if _, ok := d.fonts[f.Name]; !ok {
+		d.fonts[f.Name] = d.add(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.Name))
+	}
+	return fmt.Sprintf("F%d", d.fonts[f.Name])
+}
+
+// addPage adds a page drawn by the content stream
+func (d *pdfDoc) addPage(content []byte) {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(content)
+	zw.Close()
+	stream := d.add(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.Bytes()))
+	d.pages = append(d.pages, d.add(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>", stream)))
+}
+
+// write finishes the document with its info dictionary and cross-reference
+// table
+func (d *pdfDoc) write(w io.Writer, title, author string) error {
+	d.objects[0] = []byte("<< /Type /Catalog /Pages 2 0 R >>")
+
+	var fonts bytes.Buffer
+	for n := range d.objects {
+		if bytes.HasPrefix(d.objects[n], []byte("<< /Type /Font")) {
+			fmt.Fprintf(&fonts, " /F%d %d 0 R", n+1, n+1)
+		}
+	}
+	var kids bytes.Buffer
+	for _, n := range d.pages {
+		fmt.Fprintf(&kids, " %d 0 R", n)
+	}
+	// Every page inherits the size and fonts from the page tree
+	d.objects[1] = []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s ] /Count %d /MediaBox [0 0 %.2f %.2f] /Resources << /Font <<%s >> >> >>",
+		kids.String(), len(d.pages), d.width, d.height, fonts.String()))
+
+	info := "<< /Producer (gowrite) /Title " + pdfText(title)
+	if author != "" {
+		info += " /Author " + pdfText(author)
+	}
+	infoObj := d.add(info + " >>")
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(d.objects))
+	for i, body := range d.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, infoObj, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfString writes encoded text as a PDF literal string
+func pdfString(text []byte) string {
+	var b bytes.Buffer
+	b.WriteByte('(')
+	for _, c := range text {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// pdfText encodes document metadata, which may hold any character, as a
+// UTF-16 hex string
+func pdfText(s string) string {
+	var b bytes.Buffer
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
@@ -0,0 +1,213 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gowrite/project"
+)
+
+// siteTheme holds the colours of one of the editor's themes
+type siteTheme struct {
+	Background, Text, Accent, Notes, Wiki, Font string
+}
+
+// siteThemes match the editor themes of the same name
+var siteThemes = map[string]siteTheme{
+	"retro": {"#000000", "#33ff33", "#00ff00", "#009900", "#00aaaa", `"Courier New", Courier, monospace`},
+	"dark":  {"#111111", "#eeeeee", "#ffd700", "#e6c200", "#00aaaa", `Georgia, "Times New Roman", serif`},
+	"light": {"#ffffff", "#111111", "#00008b", "#00008b", "#008b8b", `Georgia, "Times New Roman", serif`},
+}
+
+// siteCSS is embedded in every page, so the site needs no other files
+const siteCSS = `body { background: %[1]s; color: %[2]s; font-family: %[6]s; line-height: 1.6; margin: 0; padding: 1em; }
+main, nav { max-width: 36em; margin: 0 auto; }
+h1, h2, a { color: %[3]s; }
+h1 { text-align: center; margin: 1.5em 0 1em; }
+p { margin: 0; text-indent: 1.5em; }
+h1 + p, hr + p, aside + p { text-indent: 0; }
+hr.scene-break { border: none; margin: 1em 0; text-align: center; }
+hr.scene-break::after { content: "* * *"; color: %[2]s; }
+nav { display: flex; justify-content: space-between; gap: 1em; margin: 1em auto; font-size: 0.9em; }
+nav a { text-decoration: none; }
+.byline { text-align: center; font-style: italic; }
+ol.contents { padding-left: 1.5em; }
+ol.contents li { margin: 0.3em 0; }
+aside.notes { border-left: 3px solid %[4]s; color: %[4]s; margin: 1em 0; padding-left: 1em; }
+aside.notes p { text-indent: 0; }
+.glossary main p { text-indent: 0; margin-bottom: 0.5em; }
+.glossary h1 { color: %[5]s; }
+`
+
+// sitePage is one page of the site
+type sitePage struct {
+	File, HTML string
+}
+
+// Site writes the manuscript as a static website for beta readers into dir:
+// index.html with the chapter list, a page per chapter with previous and
+// next links and, with opt.Wiki, a glossary page per Story Wiki entry. All
+// links are relative and the stylesheet is embedded, so it opens straight
+// from disk.
+func Site(dir string, p *project.Project, opt Options) error {
+	pages, err := sitePages(p, opt)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, pg := range pages {
+		if err := os.WriteFile(filepath.Join(dir, pg.File), []byte(pg.HTML), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sitePages builds the pages of the site
+func sitePages(p *project.Project, opt Options) ([]sitePage, error) {
+	theme := opt.Theme
+	if theme == "" {
+		theme = "light"
+	}
+	colours, ok := siteThemes[strings.ToLower(theme)]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q for html (use retro, dark or light)", opt.Theme)
+	}
+	css := fmt.Sprintf(siteCSS, colours.Background, colours.Text, colours.Accent, colours.Notes, colours.Wiki, colours.Font)
+	bodyClass, prose := "", proseHTML
+	if p.Mode == project.ModeScreenplay {
+		css += screenplayCSS()
+		bodyClass, prose = "screenplay", screenplayHTML
+	}
+	idx, err := opt.Chapters(p)
+	if err != nil {
+		return nil, err
+	}
+	wiki := opt.Wiki && len(p.Wiki) > 0
+
+	var pages []sitePage
+	page := func(file, title, class, body string) {
+		pages = append(pages, sitePage{File: file, HTML: htmlPage(title+" – "+p.Name(), class, css, body)})
+	}
+
+	// Index
+	var b strings.Builder
+	fmt.Fprintf(&b, "<main>\n<h1>%s</h1>\n", html.EscapeString(p.Name()))
+	if p.Metadata.Author != "" {
+		fmt.Fprintf(&b, "<p class=\"byline\">by %s</p>\n", html.EscapeString(p.Metadata.Author))
+	}
+	b.WriteString("<h2>Contents</h2>\n<ol class=\"contents\">\n")
+	for _, i := range idx {
+		fmt.Fprintf(&b, "<li value=\"%d\"><a href=\"%s\">%s</a></li>\n", i+1, chapterFile(i), html.EscapeString(chapterLabel(p, i)))
+	}
+	b.WriteString("</ol>\n")
+	if wiki {
+		b.WriteString("<p><a href=\"glossary.html\">Glossary</a></p>\n")
+	}
+	b.WriteString("</main>\n")
+	pages = append(pages, sitePage{File: "index.html", HTML: htmlPage(p.Name(), "", css, b.String())})
+
+	// Chapters, linked in reading order
+	for n, i := range idx {
+		chap := p.Chapters[i]
+		var prev, next string
+		if n > 0 {
+			prev = siteLink(chapterFile(idx[n-1]), "prev", "← "+chapterLabel(p, idx[n-1]))
+		}
+		if n < len(idx)-1 {
+			next = siteLink(chapterFile(idx[n+1]), "next", chapterLabel(p, idx[n+1])+" →")
+		}
+		nav := siteNav(prev, siteLink("index.html", "contents", "Contents"), next)
+
+		var body strings.Builder
+		body.WriteString(nav + "<main>\n")
+		fmt.Fprintf(&body, "<h1>%s</h1>\n", html.EscapeString(chapterLabel(p, i)))
+		notes := ""
+		if opt.Notes != NotesNone && strings.TrimSpace(chap.Notes) != "" {
+			notes = "<aside class=\"notes\">\n" + proseHTML(chap.Notes) + "</aside>\n"
+		}
+		if opt.Notes == NotesQuote {
+			body.WriteString(notes)
+		}
+		body.WriteString(prose(chap.Content))
+		if opt.Notes == NotesFootnote {
+			body.WriteString(notes)
+		}
+		body.WriteString("</main>\n" + nav)
+		page(chapterFile(i), chapterLabel(p, i), bodyClass, body.String())
+	}
+
+	// Glossary
+	if wiki {
+		var list strings.Builder
+		list.WriteString(siteNav("", siteLink("index.html", "contents", "Contents"), "") + "<main>\n<h1>Glossary</h1>\n<ul>\n")
+		for i, entry := range p.Wiki {
+			fmt.Fprintf(&list, "<li><a href=\"%s\">%s</a></li>\n", wikiFile(i), html.EscapeString(entry.Title))
+		}
+		list.WriteString("</ul>\n</main>\n")
+		page("glossary.html", "Glossary", "glossary", list.String())
+
+		for i, entry := range p.Wiki {
+			var prev, next string
+			if i > 0 {
+				prev = siteLink(wikiFile(i-1), "prev", "← "+p.Wiki[i-1].Title)
+			}
+			if i < len(p.Wiki)-1 {
+				next = siteLink(wikiFile(i+1), "next", p.Wiki[i+1].Title+" →")
+			}
+			nav := siteNav(prev, siteLink("glossary.html", "up", "Glossary"), next)
+			body := fmt.Sprintf("%s<main>\n<h1>%s</h1>\n%s</main>\n%s", nav, html.EscapeString(entry.Title), proseHTML(entry.Content), nav)
+			page(wikiFile(i), entry.Title, "glossary", body)
+		}
+	}
+	return pages, nil
+}
+
+// chapterFile is the page of chapter i
+func chapterFile(i int) string { return fmt.Sprintf("chapter-%d.html", i+1) }
+
+// wikiFile is the page of wiki entry i
+func wikiFile(i int) string { return fmt.Sprintf("glossary-%d.html", i+1) }
+
+// chapterLabel is "Chapter N: Title", or "Chapter N" when it has no title
+func chapterLabel(p *project.Project, i int) string {
+	if title := strings.TrimSpace(p.Chapters[i].Title); title != "" {
+		return fmt.Sprintf("Chapter %d: %s", i+1, title)
+	}
+	return fmt.Sprintf("Chapter %d", i+1)
+}
+
+// siteLink is a link with a rel attribute
+func siteLink(href, rel, text string) string {
+	return fmt.Sprintf("<a href=\"%s\" rel=\"%s\">%s</a>", href, rel, html.EscapeString(text))
+}
+
+// siteNav lays out previous, up and next links; any may be empty
+func siteNav(prev, up, next string) string {
+	return fmt.Sprintf("<nav><span>%s</span><span>%s</span><span>%s</span></nav>\n", prev, up, next)
+}
+
+// htmlPage wraps body in an HTML 5 document with the stylesheet embedded
+func htmlPage(title, class, css, body string) string {
+	if class != "" {
+		class = fmt.Sprintf(" class=\"%s\"", class)
+	}
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%s</title>
+<style>
+%s</style>
+</head>
+<body%s>
+%s</body>
+</html>
+`, html.EscapeString(title), css, class, body)
+}
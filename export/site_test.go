@@ -0,0 +1,82 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSite(t *testing.T) {
+	p := markdownProject()
+	p.AddChapter("Three")
+	p.AddWiki("Holmes")
+	dir := filepath.Join(t.TempDir(), "site")
+
+	html, _ := Lookup("html")
+	written, err := File(dir, html, p, Options{First: 2, Wiki: true, Notes: NotesQuote, Theme: "retro"})
+	if err != nil || written != dir {
+		t.Fatalf("File = %q, %v", written, err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	index := read("index.html")
+	for _, want := range []string{"<h1>The Call</h1>", "by Jane Doe", `<a href="chapter-2.html">Chapter 2: Two</a>`, `href="glossary.html"`, "#33ff33"} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.html lacks %s", want)
+		}
+	}
+	if strings.Contains(index, "chapter-1.html") {
+		t.Error("index links a chapter outside the range")
+	}
+
+	two := read("chapter-2.html")
+	if strings.Contains(two, `rel="prev"`) || !strings.Contains(two, `<a href="chapter-3.html" rel="next">`) {
+		t.Error("chapter 2 should link forward to chapter 3 only")
+	}
+	if three := read("chapter-3.html"); !strings.Contains(three, `<a href="chapter-2.html" rel="prev">`) || strings.Contains(three, `rel="next"`) {
+		t.Error("chapter 3 should link back to chapter 2 only")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "chapter-1.html")); err == nil {
+		t.Error("chapter outside the range written")
+	}
+
+	// Every relative link resolves to a page of the site
+	links := regexp.MustCompile(`href="([^"]+)"`)
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		for _, m := range links.FindAllStringSubmatch(read(e.Name()), -1) {
+			if _, err := os.Stat(filepath.Join(dir, m[1])); err != nil {
+				t.Errorf("%s links to missing %s", e.Name(), m[1])
+			}
+		}
+	}
+	if g := read("glossary-2.html"); !strings.Contains(g, "<h1>Holmes</h1>") || !strings.Contains(g, `rel="prev"`) {
+		t.Error("glossary entry page")
+	}
+
+	if _, err := File(dir, html, p, Options{Theme: "neon"}); err == nil {
+		t.Error("unknown theme accepted")
+	}
+}
+
+func TestSite_Screenplay(t *testing.T) {
+	pages, err := sitePages(screenplayProject(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chapter := pages[1].HTML
+	for _, want := range []string{`<body class="screenplay">`, ".screenplay .dialogue { margin-left: 10ch; max-width: 35ch; }", `<p class="scene-heading">INT. DINER - NIGHT</p>`} {
+		if !strings.Contains(chapter, want) {
+			t.Errorf("chapter page lacks %s", want)
+		}
+	}
+}
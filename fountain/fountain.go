@@ -0,0 +1,203 @@
+// Package fountain parses screenplays written in Fountain, the plain text
+// screenplay markup (https://fountain.io), into the elements a script is
+// laid out from.
+package fountain
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Type is the kind of a screenplay element
+type Type int
+
+// Element types
+const (
+	Action Type = iota
+	SceneHeading
+	Character
+	Parenthetical
+	Dialogue
+	Transition
+	Centered
+	Section  // "# Act One": structure only, not printed
+	Synopsis // "= The hero arrives": structure only, not printed
+	PageBreak
+)
+
+// Element is a paragraph of the script. Action and Dialogue keep their line
+// breaks; the rest are a single line.
+type Element struct {
+	Type Type
+	Text string
+}
+
+// Layout is where an element sits on the page, in characters of 12pt
+// Courier (ten to the inch) from the left margin: an action line is 60
+// characters wide.
+type Layout struct {
+	Indent, Width int
+	Upper         bool // printed in capitals
+	Right         bool // ranged right, like transitions
+	Center        bool
+}
+
+// Layouts of the printed element types, after the usual studio format
+var Layouts = map[Type]Layout{
+	Action:        {Indent: 0, Width: 60},
+	SceneHeading:  {Indent: 0, Width: 60, Upper: true},
+	Character:     {Indent: 22, Width: 38, Upper: true},
+	Parenthetical: {Indent: 16, Width: 19},
+	Dialogue:      {Indent: 10, Width: 35},
+	Transition:    {Indent: 0, Width: 60, Upper: true, Right: true},
+	Centered:      {Indent: 0, Width: 60, Center: true},
+}
+
+// Printed reports whether an element appears in the finished script
+func (e Element) Printed() bool {
+	_, ok := Layouts[e.Type]
+	return ok
+}
+
+// Joined reports whether an element follows the one before it without a
+// blank line: dialogue and parentheticals under their character cue
+func (e Element) Joined(prev Element) bool {
+	switch e.Type {
+	case Dialogue, Parenthetical:
+		return prev.Type == Character || prev.Type == Dialogue || prev.Type == Parenthetical
+	}
+	return false
+}
+
+// sceneHeadingRegex matches the scene heading prefixes
+var sceneHeadingRegex = regexp.MustCompile(`(?i)^(INT|EXT|EST|INT\.?/EXT|I/E)[. ]`)
+
+// commentRegex matches [[notes]] and /* boneyard */, which are not printed
+var commentRegex = regexp.MustCompile(`(?s)\[\[.*?\]\]|/\*.*?\*/`)
+
+// Parse splits a script into elements
+func Parse(text string) []Element {
+	text = commentRegex.ReplaceAllString(strings.ReplaceAll(text, "\r\n", "\n"), "")
+	lines := strings.Split(text, "\n")
+	blank := func(i int) bool {
+		return i < 0 || i >= len(lines) || strings.TrimSpace(lines[i]) == ""
+	}
+
+	var out []Element
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "":
+		case len(line) >= 3 && strings.Trim(line, "=") == "":
+			out = append(out, Element{Type: PageBreak})
+		case strings.HasPrefix(line, "#"):
+			out = append(out, Element{Type: Section, Text: strings.TrimSpace(strings.TrimLeft(line, "#"))})
+		case strings.HasPrefix(line, "="):
+			out = append(out, Element{Type: Synopsis, Text: strings.TrimSpace(line[1:])})
+		case strings.HasPrefix(line, ">") && strings.HasSuffix(line, "<") && len(line) > 1:
+			out = append(out, Element{Type: Centered, Text: strings.TrimSpace(line[1 : len(line)-1])})
+		case strings.HasPrefix(line, ">"):
+			out = append(out, Element{Type: Transition, Text: strings.TrimSpace(line[1:])})
+		case strings.HasPrefix(line, "!"):
+			out = action(out, line[1:], blank(i-1))
+		case blank(i-1) && isSceneHeading(line):
+			out = append(out, Element{Type: SceneHeading, Text: strings.TrimPrefix(line, ".")})
+		case blank(i-1) && blank(i+1) && isUpper(line) && strings.HasSuffix(line, "TO:"):
+			out = append(out, Element{Type: Transition, Text: line})
+		case blank(i-1) && !blank(i+1) && isCharacter(line):
+			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "@"), "^"))
+			out = append(out, Element{Type: Character, Text: name})
+			for ; !blank(i + 1); i++ {
+				next := strings.TrimSpace(lines[i+1])
+				last := &out[len(out)-1]
+				switch {
+				case strings.HasPrefix(next, "(") && strings.HasSuffix(next, ")"):
+					out = append(out, Element{Type: Parenthetical, Text: next})
+				case last.Type == Dialogue:
+					last.Text += "\n" + next
+				default:
+					out = append(out, Element{Type: Dialogue, Text: next})
+				}
+			}
+		default:
+			out = action(out, line, blank(i-1))
+		}
+	}
+	return out
+}
+
+// action adds a line of action, continuing the paragraph above unless a
+// blank line came before
+func action(out []Element, line string, newParagraph bool) []Element {
+	if n := len(out); n > 0 && !newParagraph && out[n-1].Type == Action {
+		out[n-1].Text += "\n" + line
+		return out
+	}
+	return append(out, Element{Type: Action, Text: line})
+}
+
+// isSceneHeading reports whether line starts a scene: INT., EXT. and the
+// like, or any line forced with a leading "."
+func isSceneHeading(line string) bool {
+	if strings.HasPrefix(line, ".") {
+		return len(line) > 1 && line[1] != '.'
+	}
+	return sceneHeadingRegex.MatchString(line)
+}
+
+// isCharacter reports whether line is a character cue: a name in capitals,
+// perhaps with an extension like "(V.O.)", or any line forced with "@"
+func isCharacter(line string) bool {
+	if strings.HasPrefix(line, "@") {
+		return len(line) > 1
+	}
+	name := line
+	if i := strings.Index(name, "("); i > 0 {
+		name = name[:i]
+	}
+	return isUpper(strings.TrimSuffix(strings.TrimSpace(name), "^"))
+}
+
+// isUpper reports whether s has letters and all of them are capitals
+func isUpper(s string) bool {
+	letters := false
+	for _, r := range s {
+		if unicode.IsLower(r) {
+			return false
+		}
+		letters = letters || unicode.IsLetter(r)
+	}
+	return letters
+}
+
+// Wrap breaks text into lines of at most width characters, at spaces where
+// it can. Line breaks in text are kept.
+func Wrap(text string, width int) []string {
+	var out []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for len([]rune(word)) > width {
+				if line != "" {
+					out = append(out, line)
+					line = ""
+				}
+				r := []rune(word)
+				out = append(out, string(r[:width]))
+				word = string(r[width:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= width:
+				line += " " + word
+			default:
+				out = append(out, line)
+				line = word
+			}
+		}
+		out = append(out, line)
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package fountain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	script := `# Act One
+
+= Brick meets Laura.
+
+INT. DINER - NIGHT
+
+Rain streaks the window.
+A neon sign buzzes.
+
+BRICK (V.O.)
+(quietly)
+She came in at nine.
+Ordered coffee.
+
+@McCLANE
+Yippee.
+
+BANG! [[Make it louder.]]
+
+/* Cut scene
+INT. NOWHERE */
+
+CUT TO:
+
+.FLASHBACK
+
+> THE END <
+
+===
+
+> SMASH CUT TO:
+!EXT. NOT A HEADING`
+
+	want := []Element{
+		{Section, "Act One"},
+		{Synopsis, "Brick meets Laura."},
+		{SceneHeading, "INT. DINER - NIGHT"},
+		{Action, "Rain streaks the window.\nA neon sign buzzes."},
+		{Character, "BRICK (V.O.)"},
+		{Parenthetical, "(quietly)"},
+		{Dialogue, "She came in at nine.\nOrdered coffee."},
+		{Character, "McCLANE"},
+		{Dialogue, "Yippee."},
+		{Action, "BANG!"},
+		{Transition, "CUT TO:"},
+		{SceneHeading, "FLASHBACK"},
+		{Centered, "THE END"},
+		{PageBreak, ""},
+		{Transition, "SMASH CUT TO:"},
+		{Action, "EXT. NOT A HEADING"},
+	}
+	if got := Parse(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestParse_NeedsBlankLines(t *testing.T) {
+	// A capitalised line with nothing under it is action, and a scene
+	// heading prefix mid-paragraph is not a heading
+	got := Parse("He reads the sign:\nINT. ONLY\n\nSTOP")
+	want := []Element{{Action, "He reads the sign:\nINT. ONLY"}, {Action, "STOP"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestElement_Joined(t *testing.T) {
+	cue := Element{Type: Character}
+	if !(Element{Type: Dialogue}).Joined(cue) || !(Element{Type: Parenthetical}).Joined(Element{Type: Dialogue}) {
+		t.Error("dialogue should follow its cue directly")
+	}
+	if (Element{Type: Action}).Joined(Element{Type: Dialogue}) {
+		t.Error("action after dialogue needs a blank line")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	got := Wrap("one two three four\nfive abcdefghij", 9)
+	want := []string{"one two", "three", "four", "five", "abcdefghi", "j"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrap = %q, want %q", got, want)
+	}
+}
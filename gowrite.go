@@ -3,76 +3,91 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 	"unicode"
 
+	"gowrite/export"
+	"gowrite/fountain"
+	"gowrite/importer"
+	"gowrite/project"
+	"gowrite/search"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-// Chapter represents a section of the document
-type Chapter struct {
-	Title   string
-	Content string
-	Notes   string
-	Target  int
-}
-
-// WikiEntry represents a single item in the Story Wiki
-type WikiEntry struct {
-	Title   string
-	Content string
-}
-
-// Project represents the full save file structure (Chapters + Wiki)
-type Project struct {
-	Chapters []Chapter
-	Wiki     []WikiEntry
-}
-
 // View state constants
 const (
 	ViewMain = iota
 	ViewNotes
 	ViewAnalyze
 	ViewWiki
+	ViewScript // screenplay preview, shown in the analysis viewer
 )
 
 // TargetWidth is the centered view column width
 const TargetWidth = 85
 
-// CalculateReadability computes ARI grade level and returns age range
-func CalculateReadability(text string) string {
-	words := len(strings.Fields(text))
-	sentences := strings.Count(text, ".") + strings.Count(text, "!") + strings.Count(text, "?")
-	if sentences == 0 {
-		sentences = 1
-	}
+// ScriptWidth is the width of the script pane: a page's 60 columns, with
+// its border and padding
+const ScriptWidth = 66
 
-	chars := 0
-	for _, r := range text {
-		if !unicode.IsSpace(r) {
-			chars++
+// viewNames are the views that can be restored or chosen with --view
+var viewNames = map[int]string{
+	ViewMain:  "main",
+	ViewNotes: "notes",
+	ViewWiki:  "wiki",
+}
+
+// parseView looks up a view by name
+func parseView(name string) (int, bool) {
+	for v, n := range viewNames {
+		if strings.EqualFold(n, name) {
+			return v, true
 		}
 	}
-	if words == 0 {
-		words = 1
-	}
+	return ViewMain, false
+}
 
-	ari := 4.71*(float64(chars)/float64(words)) + 0.5*(float64(words)/float64(sentences)) - 21.43
-	grade := int(math.Ceil(ari))
-	if grade < 1 {
-		grade = 1
+// every runs f through queue, normally onto the UI goroutine, once per
+// interval until stop is closed
+func every(interval time.Duration, queue func(func()), f func(), stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			queue(f)
+		case <-stop:
+			return
+		}
 	}
+}
+
+// Prose analysis patterns shared by AnalyzeTextForHemingway and HemingwayStats
+var (
+	adverbRegex   = regexp.MustCompile(`(?i)\b(\w+ly)\b`)
+	passiveRegex  = regexp.MustCompile(`(?i)\b(am|are|is|was|were|be|been|being)\b\s+(\w+ed)\b`)
+	sentenceRegex = regexp.MustCompile(`[^.!?]+[.!?]*`)
+)
+
+// DictionaryFile is the word list spellcheck reads from the working directory
+const DictionaryFile = "dictionary.txt"
+
+// CalculateReadability computes ARI grade level and returns age range
+func CalculateReadability(text string) string {
+	grade := ReadabilityGrade(text)
 
 	ageRange := "Adult"
 	switch grade {
@@ -109,11 +124,34 @@ func CalculateReadability(text string) string {
 	return fmt.Sprintf("Reading Age: %s (Grade %d)", ageRange, grade)
 }
 
+// ReadabilityGrade computes the ARI grade level of text (minimum 1)
+func ReadabilityGrade(text string) int {
+	words := len(strings.Fields(text))
+	sentences := strings.Count(text, ".") + strings.Count(text, "!") + strings.Count(text, "?")
+	if sentences == 0 {
+		sentences = 1
+	}
+
+	chars := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			chars++
+		}
+	}
+	if words == 0 {
+		words = 1
+	}
+
+	ari := 4.71*(float64(chars)/float64(words)) + 0.5*(float64(words)/float64(sentences)) - 21.43
+	grade := int(math.Ceil(ari))
+	if grade < 1 {
+		grade = 1
+	}
+	return grade
+}
+
 // AnalyzeTextForHemingway returns text with color markup for prose issues
 func AnalyzeTextForHemingway(text string) string {
-	adverbRegex := regexp.MustCompile(`(?i)\b(\w+ly)\b`)
-	passiveRegex := regexp.MustCompile(`(?i)\b(am|are|is|was|were|be|been|being)\b\s+(\w+ed)\b`)
-
 	paragraphs := strings.Split(text, "\n")
 	var processedText strings.Builder
 
@@ -123,8 +161,7 @@ func AnalyzeTextForHemingway(text string) string {
 			continue
 		}
 
-		sentenceRe := regexp.MustCompile(`[^.!?]+[.!?]*`)
-		matches := sentenceRe.FindAllString(para, -1)
+		matches := sentenceRegex.FindAllString(para, -1)
 
 		for _, s := range matches {
 			wordCount := len(strings.Fields(s))
@@ -157,7 +194,556 @@ func AnalyzeTextForHemingway(text string) string {
 	return processedText.String()
 }
 
+// RenderScreenplay lays out a Fountain script the way it prints, for the
+// preview: each element at its indent, cues and headings in capitals, with
+// color markup. Sections and synopses are shown dimmed though they do not
+// print.
+func RenderScreenplay(text string) string {
+	script, _ := layoutScreenplay(text)
+	return script
+}
+
+// layoutScreenplay is RenderScreenplay, also giving the row of the script
+// each element starts on
+func layoutScreenplay(text string) (string, []int) {
+	colors := map[fountain.Type]string{
+		fountain.SceneHeading:  "[yellow::b]",
+		fountain.Character:     "[::b]",
+		fountain.Parenthetical: "[gray]",
+		fountain.Transition:    "[yellow]",
+		fountain.Section:       "[gray::i]# ",
+		fountain.Synopsis:      "[gray::i]= ",
+	}
+
+	var b strings.Builder
+	var starts []int
+	row := 0
+	var prev fountain.Element
+	for i, e := range fountain.Parse(text) {
+		if i > 0 && !e.Joined(prev) {
+			b.WriteString("\n")
+			row++
+		}
+		prev = e
+		starts = append(starts, row)
+		if e.Type == fountain.PageBreak {
+			b.WriteString("[gray]" + strings.Repeat("─", 60) + "[-]\n")
+			row++
+			continue
+		}
+
+		layout, ok := fountain.Layouts[e.Type]
+		if !ok {
+			layout = fountain.Layout{Width: 58}
+		}
+		text := e.Text
+		if layout.Upper {
+			text = strings.ToUpper(text)
+		}
+		for _, line := range fountain.Wrap(text, layout.Width) {
+			pad := layout.Indent
+			switch {
+			case layout.Right:
+				pad += layout.Width - len([]rune(line))
+			case layout.Center:
+				pad += (layout.Width - len([]rune(line))) / 2
+			}
+			b.WriteString(strings.Repeat(" ", pad) + colors[e.Type] + tview.Escape(line) + "[-:-:-]\n")
+			row++
+		}
+	}
+	return b.String(), starts
+}
+
+// scriptRow is the row the element at offset in a Fountain text starts on,
+// in the script layoutScreenplay made of it with starts. The elements up to
+// the end of offset's line are counted, so a cursor at the start of a line
+// is in that line's element.
+func scriptRow(text string, offset int, starts []int) int {
+	if end := strings.IndexByte(text[offset:], '\n'); end >= 0 {
+		text = text[:offset+end]
+	}
+	i := min(len(fountain.Parse(text)), len(starts)) - 1
+	if i < 0 {
+		return 0
+	}
+	return starts[i]
+}
+
+// HemingwayReport counts the prose issues AnalyzeTextForHemingway highlights
+type HemingwayReport struct {
+	Sentences int
+	Adverbs   int
+	Passive   int
+	Hard      int // more than 14 words
+	VeryHard  int // more than 20 words
+}
+
+// HemingwayStats counts prose issues without producing markup
+func HemingwayStats(text string) HemingwayReport {
+	var r HemingwayReport
+	for _, para := range strings.Split(text, "\n") {
+		if strings.TrimSpace(para) == "" {
+			continue
+		}
+		for _, s := range sentenceRegex.FindAllString(para, -1) {
+			if strings.TrimSpace(s) == "" {
+				continue
+			}
+			r.Sentences++
+			wordCount := len(strings.Fields(s))
+			if wordCount > 20 {
+				r.VeryHard++
+			} else if wordCount > 14 {
+				r.Hard++
+			}
+			r.Adverbs += len(adverbRegex.FindAllString(s, -1))
+			r.Passive += len(passiveRegex.FindAllString(s, -1))
+		}
+	}
+	return r
+}
+
+// TextStats holds the counts shown by the wordcount command
+type TextStats struct {
+	Words int
+	Chars int
+	Lines int
+}
+
+// CountText returns word, character (byte) and line counts for text
+func CountText(text string) TextStats {
+	lines := strings.Count(text, "\n") + 1
+	if len(text) == 0 {
+		lines = 0
+	}
+	return TextStats{Words: len(strings.Fields(text)), Chars: len(text), Lines: lines}
+}
+
+// goalProgress shows words written against a target, "850/1500 57%", and
+// reports whether the target is met
+func goalProgress(words, target int) (string, bool) {
+	return fmt.Sprintf("%d/%d %d%%", words, target, words*100/target), words >= target
+}
+
+// chapterLine lays out chapter n for the Chapter Manager: its number and
+// title, marked when it is the one being edited, and its words against its
+// target
+func chapterLine(n int, c project.Chapter, words int, current bool) string {
+	marker := " "
+	if current {
+		marker = "▸"
+	}
+	title := []rune(c.Title)
+	if len(title) > 30 {
+		title = append(title[:29], '…')
+	}
+	count := strconv.Itoa(words)
+	if c.Target > 0 {
+		count, _ = goalProgress(words, c.Target)
+	}
+	return tview.Escape(fmt.Sprintf("%s%3d. %-30s %16s", marker, n, string(title), count))
+}
+
+// progressBar draws done out of goal as a bar width cells wide
+func progressBar(done, goal, width int) string {
+	filled := min(done*width/goal, width)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// historyReport lays out a project's writing history for the history view:
+// today against the daily goal, the current session, the streak and pace,
+// then the words written each day, newest first
+func historyReport(h *project.History, s *project.Session, daily int) string {
+	var b strings.Builder
+	today := fmt.Sprintf("%d words", h.Today())
+	if daily > 0 {
+		progress, met := goalProgress(h.Today(), daily)
+		today = progress + " of the daily goal"
+		if met {
+			today += " [green]✓[white]"
+		}
+	}
+	fmt.Fprintf(&b, "[yellow]Today[white]         %s\n", today)
+	fmt.Fprintf(&b, "[yellow]This session[white]  %+d (%d added, %d deleted)", s.Words(), s.Added(), s.Deleted())
+	if !s.Start.IsZero() {
+		fmt.Fprintf(&b, " since %s", s.Start.Format("15:04"))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "[yellow]Streak[white]        %s\n", plural(h.Streak(daily), "day", "days"))
+	if pace := h.Pace(); pace > 0 {
+		fmt.Fprintf(&b, "[yellow]Pace[white]          %d words an hour\n", pace)
+	}
+
+	for i, c := range s.Chapters {
+		if i == 0 {
+			b.WriteString("\n[green]This session by chapter[white]\n")
+		}
+		fmt.Fprintf(&b, "%-34s %6s %6s\n", tview.Escape(c.Title), fmt.Sprintf("+%d", c.Added), fmt.Sprintf("-%d", c.Deleted))
+	}
+
+	for i := len(h.Sprints) - 1; i >= max(len(h.Sprints)-5, 0); i-- {
+		if i == len(h.Sprints)-1 {
+			b.WriteString("\n[green]Sprints[white]\n")
+		}
+		sp := h.Sprints[i]
+		fmt.Fprintf(&b, "%s %3d min %6s", sp.Start.Local().Format("2006-01-02 15:04"), sp.Minutes, fmt.Sprintf("%+d", sp.Words))
+		if !sp.Finished() {
+			b.WriteString(" (stopped)")
+		}
+		b.WriteString("\n")
+	}
+
+	days := h.Days()
+	if len(days) == 0 {
+		b.WriteString("\nNothing written yet.")
+		return b.String()
+	}
+	most := 1
+	for _, d := range days {
+		most = max(most, d.Words)
+	}
+	b.WriteString("\n[green]Words per day[white]\n")
+	for i := len(days) - 1; i >= 0; i-- {
+		d := days[i]
+		date, _ := time.Parse("2006-01-02", d.Date)
+		fmt.Fprintf(&b, "%s %s %6d %s\n", d.Date, date.Format("Mon"), d.Words, progressBar(max(d.Words, 0), most, 20))
+	}
+	return b.String()
+}
+
+// sprintClock shows the time left in a sprint, "24:07"
+func sprintClock(left time.Duration) string {
+	left = max(left, 0).Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(left.Minutes()), int(left.Seconds())%60)
+}
+
+// sprintSummary reports how a sprint went
+func sprintSummary(s project.Sprint) string {
+	ran := s.End.Sub(s.Start)
+	summary := fmt.Sprintf("%s: %+d words", plural(s.Minutes, "minute", "minutes"), s.Words)
+	if !s.Finished() {
+		summary = fmt.Sprintf("Stopped after %s of %s", sprintClock(ran), summary)
+	}
+	if ran >= time.Minute {
+		summary += fmt.Sprintf(", %d an hour", int(float64(s.Words)/ran.Hours()))
+	}
+	return summary
+}
+
+// deadlineReport lays out a deadline plan for the deadline view: the days
+// left, the manuscript against its target, the words needed a day and when
+// the recent pace would finish it
+func deadlineReport(plan project.Plan, target int) string {
+	var b strings.Builder
+	left := "passed"
+	if plan.DaysLeft > 0 {
+		left = plural(plan.DaysLeft, "day", "days") + " left, counting today"
+	}
+	fmt.Fprintf(&b, "[yellow]Deadline[white]      %s (%s)\n", plan.Deadline.Format("Mon 2 Jan 2006"), left)
+	progress, met := goalProgress(plan.Words, target)
+	fmt.Fprintf(&b, "[yellow]Manuscript[white]    %s %s\n", progress, progressBar(max(plan.Words, 0), target, 20))
+	if met {
+		b.WriteString("\n[green]Target reached ✓[white]\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "[yellow]To write[white]      %d words\n", target-plan.Words)
+	today, done := goalProgress(plan.Today, max(plan.PerDay, 1))
+	if done {
+		today += " [green]✓[white]"
+	}
+	fmt.Fprintf(&b, "[yellow]Needed[white]        %d words a day (today %s)\n", plan.PerDay, today)
+	if plan.Pace <= 0 {
+		b.WriteString("[yellow]Recent pace[white]   nothing written in the last two weeks\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "[yellow]Recent pace[white]   %d words a day over the last two weeks\n", plan.Pace)
+
+	projected := plan.Projected.Format("Mon 2 Jan 2006")
+	switch late := int(plan.Projected.Sub(plan.Deadline).Hours()/24 + 0.5); {
+	case late > 0:
+		projected += fmt.Sprintf(", [red]%s late[white]", plural(late, "day", "days"))
+	case late < 0:
+		projected += fmt.Sprintf(", [green]%s early[white]", plural(-late, "day", "days"))
+	default:
+		projected += ", [green]on the day[white]"
+	}
+	fmt.Fprintf(&b, "[yellow]Projected[white]     %s\n", projected)
+	return b.String()
+}
+
+// LoadDictionary reads a word list, one word per line
+func LoadDictionary(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dictionary := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		dictionary[strings.TrimSpace(strings.ToLower(scanner.Text()))] = true
+	}
+	return dictionary, scanner.Err()
+}
+
+// FindMisspellings returns the sorted, lower-cased words of text that are
+// not in dictionary. A trailing "s" is forgiven when the singular is known.
+func FindMisspellings(text string, dictionary map[string]bool) []string {
+	unknowns := make(map[string]bool)
+	for _, rawWord := range strings.Fields(text) {
+		cleanWord := strings.TrimFunc(rawWord, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		cleanWord = strings.ToLower(cleanWord)
+		if cleanWord == "" {
+			continue
+		}
+		if !dictionary[cleanWord] {
+			if strings.HasSuffix(cleanWord, "s") && dictionary[strings.TrimSuffix(cleanWord, "s")] {
+				continue
+			}
+			unknowns[cleanWord] = true
+		}
+	}
+
+	list := make([]string, 0, len(unknowns))
+	for w := range unknowns {
+		list = append(list, w)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// parseSplitArgs reads the arguments of "import split": a file name and the
+// --pattern and --notes options, in any order
+func parseSplitArgs(args []string) (string, importer.Options, error) {
+	var opt importer.Options
+	var name []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--notes":
+			opt.Notes = true
+		case arg == "--pattern" || strings.HasPrefix(arg, "--pattern="):
+			expr, ok := strings.CutPrefix(arg, "--pattern=")
+			if !ok {
+				if i+1 >= len(args) {
+					return "", opt, errors.New("--pattern needs a regular expression")
+				}
+				i++
+				expr = args[i]
+			}
+			re, err := importer.ParsePattern(expr)
+			if err != nil {
+				return "", opt, err
+			}
+			opt.Pattern = re
+		default:
+			name = append(name, arg)
+		}
+	}
+	fn, ok := validateImport(strings.Join(name, " "))
+	if len(name) == 0 || !ok {
+		return "", opt, errors.New("only .txt, .md, .docx and .odt files can be split")
+	}
+	return fn, opt, nil
+}
+
+// parseSearchArgs reads the arguments of "search": the -c (match case), -w
+// (whole word) and -r (regular expression) options, then the term
+func parseSearchArgs(args []string) (string, search.Options) {
+	var opt search.Options
+	for len(args) > 0 {
+		switch args[0] {
+		case "-c", "--case":
+			opt.CaseSensitive = true
+		case "-w", "--word":
+			opt.WholeWord = true
+		case "-r", "--regex":
+			opt.Regex = true
+		default:
+			return strings.Join(args, " "), opt
+		}
+		args = args[1:]
+	}
+	return "", opt
+}
+
+// splitQuoted splits s at spaces, except inside double quotes, so that
+// phrases can be given whole: replace "Mr Smith" "Dr Jones"
+func splitQuoted(s string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	inField, quoted := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inField = true
+		case unicode.IsSpace(r) && !quoted:
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
+
+// parseReplaceArgs reads the arguments of "replace": the options of
+// "search", --all for the whole project, then the term and what replaces it
+func parseReplaceArgs(raw string) (term, with string, opt search.Options, all bool, err error) {
+	args, err := splitQuoted(raw)
+	if err != nil {
+		return "", "", opt, false, err
+	}
+	// Options come first; the last two arguments are always the terms
+	for len(args) > 2 {
+		switch args[0] {
+		case "-c", "--case":
+			opt.CaseSensitive = true
+		case "-w", "--word":
+			opt.WholeWord = true
+		case "-r", "--regex":
+			opt.Regex = true
+		case "-a", "--all":
+			all = true
+		default:
+			return "", "", opt, false, fmt.Errorf("unknown option %s", args[0])
+		}
+		args = args[1:]
+	}
+	if len(args) != 2 {
+		return "", "", opt, false, errors.New(`usage: replace [-c] [-w] [-r] [--all] <term> <replacement>; quote phrases: replace "Mr Smith" "Dr Jones"`)
+	}
+	return args[0], args[1], opt, all, nil
+}
+
+// hitLabel says where a search hit is, for results lists
+func hitLabel(p *project.Project, h search.Hit) string {
+	switch h.Field {
+	case search.Notes:
+		return fmt.Sprintf("Chapter %d: %s (notes), line %d", h.Index+1, p.Chapters[h.Index].Title, h.Line)
+	case search.Wiki:
+		return fmt.Sprintf("Wiki: %s, line %d", p.Wiki[h.Index].Title, h.Line)
+	}
+	return fmt.Sprintf("Chapter %d: %s, line %d", h.Index+1, p.Chapters[h.Index].Title, h.Line)
+}
+
+// showInEditor puts a chapter's text into the editor. The session follows
+// the chapter first, so text loaded or imported is not counted as written.
+func showInEditor(area *tview.TextArea, s *project.Session, c project.Chapter) {
+	s.Follow(c.Title, CountText(c.Content).Words)
+	area.SetText(c.Content, false)
+}
+
+// replaceInEditor swaps the editor's text for a chapter's changed in the
+// project, as one step for the editor's undo. Like showInEditor it follows
+// the chapter first, so words replaced are not counted as written.
+func replaceInEditor(area *tview.TextArea, s *project.Session, c project.Chapter) {
+	if area.GetText() == c.Content {
+		return
+	}
+	s.Follow(c.Title, CountText(c.Content).Words)
+	area.Replace(0, area.GetTextLength(), c.Content)
+}
+
+// hitView is the view a search hit is opened in
+func hitView(h search.Hit) int {
+	switch h.Field {
+	case search.Wiki:
+		return ViewWiki
+	case search.Notes:
+		return ViewNotes
+	}
+	return ViewMain
+}
+
+// hitContext shows the line around a search hit with the match underlined,
+// escaped for a tview list
+func hitContext(h search.Hit) string {
+	end := min(h.Before+h.Match[1]-h.Match[0], len(h.Context))
+	return tview.Escape(h.Context[:h.Before]) + "[::u]" + tview.Escape(h.Context[h.Before:end]) + "[::-]" + tview.Escape(h.Context[end:])
+}
+
+// markMatches gives every match of m in sight in area the marked style,
+// once area is drawn on screen. The text area cannot style parts of its
+// text, so the screen is read back a row at a time: a match wrapped over
+// two rows is not marked. Cells not in the plain text style, the selected
+// match and the cursor, are left as they are.
+func markMatches(screen tcell.Screen, area *tview.TextArea, m *search.Matcher, marked tcell.Style) {
+	plain := area.GetTextStyle()
+	x, y, width, height := area.GetInnerRect()
+	for row := y; row < y+height; row++ {
+		var line strings.Builder
+		var cols []int // the screen column each byte of line is in
+		for col := x; col < x+width; {
+			str, _, w := screen.Get(col, row)
+			if str == "" {
+				str = " "
+			}
+			for range len(str) {
+				cols = append(cols, col)
+			}
+			line.WriteString(str)
+			col += max(w, 1)
+		}
+		for _, match := range m.All(line.String()) {
+			for i := match[0]; i < match[1]; i++ {
+				if i > match[0] && cols[i] == cols[i-1] {
+					continue
+				}
+				if str, style, _ := screen.Get(cols[i], row); style == plain {
+					screen.Put(cols[i], row, str, marked)
+				}
+			}
+		}
+	}
+}
+
+// plural counts n things, as "1 chapter" or "2 chapters"
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// validateImport cleans an import path and reports whether it can be
+// imported: text, Markdown, Word, OpenDocument or a Scrivener project
+func validateImport(raw string) (string, bool) {
+	fn := strings.Join(strings.Fields(raw), " ")
+	fn = filepath.Clean(fn)
+	return fn, slices.Contains(importer.Extensions, strings.ToLower(filepath.Ext(fn))) || importer.IsScrivener(fn)
+}
+
 func main() {
+	// Headless subcommands run without starting the terminal UI
+	if code, ok := runCLI(os.Args[1:], os.Stdout, os.Stderr); ok {
+		os.Exit(code)
+	}
+	startArgs, err := parseEditorArgs(os.Args[1:])
+	if err != nil {
+		if err != errUsage {
+			fmt.Fprintf(os.Stderr, "gowrite: %v\n", err)
+		}
+		fmt.Fprintf(os.Stderr, "usage: %s\n", editorUsage)
+		os.Exit(2)
+	}
+
 	// --- 0. THEME SETUP ---
 	tview.Styles.PrimitiveBackgroundColor = tcell.ColorBlack
 	tview.Styles.ContrastBackgroundColor = tcell.ColorDarkBlue
@@ -175,26 +761,40 @@ func main() {
 
 	// --- 1. Data Management ---
 
-	chapters := []Chapter{
-		{Title: "The Beginning", Content: "", Notes: "", Target: 0},
-	}
-
-	wikiEntries := []WikiEntry{
-		{Title: "General Notes", Content: ""},
-	}
-
-	currentChapterIndex := 0
-	currentWikiIndex := 0
-	currentFilename := ""
+	// The project and the widgets belong to the UI goroutine. Background
+	// goroutines only read files or work on a Clone of the project, and hand
+	// their results back through queueUpdate; nothing here needs a lock.
+	book := project.New()
+	queueUpdate := func(f func()) { app.QueueUpdateDraw(f) }
+	stop := make(chan struct{}) // closed when the editor exits
 	currentView := ViewMain
 
 	// Visual States
 	isCenteredView := false
 	isFocusMode := false // Hides all UI chrome
-
-	dictionary := make(map[string]bool)
-	dictionaryLoaded := false
-	var mu sync.Mutex
+	sprintFocus := false // Whether a sprint turned focus mode on, to turn it off again
+	currentTheme := ""
+
+	// Recovery journal: the project is fingerprinted when saved or loaded and
+	// when journaled, so only unsaved, unjournaled changes are written out
+	savedSum := ""
+	journalSum := ""
+	journalPath := ""
+	journaler := project.NewJournaler()
+
+	// Writing sessions: the words added and deleted since the project was
+	// opened, and the project's history of earlier sessions
+	session := &project.Session{}
+	history := &project.History{}
+	// Words in every chapter but the one in the editor, counted again only
+	// once the chapters change behind it; -1 until then
+	otherWords := -1
+	// The writing sprint under way, if any, and the session's words when it
+	// began
+	var sprint *project.Sprint
+	sprintBase := 0
+
+	var dictionary map[string]bool
 
 	// --- 2. Setup Main Components ---
 
@@ -203,7 +803,7 @@ func main() {
 	textArea.SetWrap(true)
 	textArea.SetPlaceholder("Start writing your masterpiece...")
 	textArea.SetTextStyle(tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorWhite))
-	textArea.SetTitle(fmt.Sprintf("gowrite - Chapter 1: %s", chapters[0].Title))
+	textArea.SetTitle(fmt.Sprintf("gowrite - Chapter 1: %s", book.Chapters[0].Title))
 	textArea.SetBorder(true)
 	textArea.SetBorderPadding(1, 1, 2, 2)
 
@@ -242,6 +842,18 @@ func main() {
 	analysisView.SetBorder(true)
 	analysisView.SetBorderPadding(1, 1, 2, 2)
 
+	// SCRIPT PANE (Read Only): in screenplay mode, the chapter laid out as
+	// it prints, beside the editor and kept up with it
+	scriptView := tview.NewTextView()
+	scriptView.SetDynamicColors(true)
+	scriptView.SetWrap(false)
+	scriptView.SetTitle("SCRIPT")
+	scriptView.SetBorder(true)
+	scriptView.SetBorderPadding(1, 1, 2, 2)
+	scriptPane := tview.NewFlex()
+	scriptPane.AddItem(textArea, 0, 1, true)
+	scriptPane.AddItem(scriptView, ScriptWidth, 0, false)
+
 	commandPalette := tview.NewInputField()
 	commandPalette.SetLabel(" > ")
 	commandPalette.SetFieldBackgroundColor(tcell.ColorBlack)
@@ -276,6 +888,7 @@ func main() {
 	applyTheme := func(name string) {
 		name = strings.ToLower(name)
 		analysisView.SetBackgroundColor(tcell.ColorBlack)
+		scriptView.SetBackgroundColor(tcell.ColorBlack)
 
 		switch name {
 		case "light":
@@ -296,6 +909,7 @@ func main() {
 			wikiArea.SetBackgroundColor(tcell.ColorWhite)
 
 			analysisView.SetBackgroundColor(tcell.ColorWhite)
+			scriptView.SetBackgroundColor(tcell.ColorWhite)
 
 			commandPalette.SetFieldBackgroundColor(tcell.ColorWhite).SetFieldTextColor(tcell.ColorBlack).SetBackgroundColor(tcell.ColorWhite)
 			helpInfo.SetTextColor(tcell.ColorDarkGray).SetBackgroundColor(tcell.ColorWhite)
@@ -317,6 +931,7 @@ func main() {
 			wikiArea.SetBackgroundColor(tcell.ColorBlack)
 
 			analysisView.SetBackgroundColor(tcell.ColorBlack)
+			scriptView.SetBackgroundColor(tcell.ColorBlack)
 
 			commandPalette.SetFieldBackgroundColor(tcell.ColorBlack).SetFieldTextColor(tcell.ColorGreen).SetBackgroundColor(tcell.ColorBlack)
 			helpInfo.SetTextColor(tcell.ColorGreen).SetBackgroundColor(tcell.ColorBlack)
@@ -338,24 +953,44 @@ func main() {
 			wikiArea.SetBackgroundColor(tcell.ColorBlack)
 
 			analysisView.SetBackgroundColor(tcell.ColorBlack)
+			scriptView.SetBackgroundColor(tcell.ColorBlack)
 
 			commandPalette.SetFieldBackgroundColor(tcell.ColorBlack).SetFieldTextColor(tcell.ColorWhite).SetBackgroundColor(tcell.ColorBlack)
 			helpInfo.SetTextColor(tcell.ColorDarkGray).SetBackgroundColor(tcell.ColorBlack)
 			position.SetBackgroundColor(tcell.ColorBlack)
+
+		default:
+			return
 		}
+		currentTheme = name
 	}
 	applyTheme("retro")
 
 	// --- 4. Logic & Helper Functions ---
 
+	// updateInfos refreshes the status bar; it is set up with the editors
+	var updateInfos func()
+	// The screen, once drawn, to ring the bell on
+	var terminal tcell.Screen
+
 	// VIEW RESIZE LOGIC
 	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		terminal = screen
 		w, _ := screen.Size()
 
+		// The script pane gives way to leave the editor 40 columns, down to
+		// half the screen
+		script := min(ScriptWidth, max(w-40, w/2))
+		scriptPane.ResizeItem(scriptView, script, 0)
+		editor := w
+		if currentView == ViewMain && book.Mode == project.ModeScreenplay {
+			editor -= script
+		}
+
 		var hPadding int
 		// If centered view is ON and screen is wide enough to justify it
-		if isCenteredView && w > TargetWidth+4 {
-			hPadding = (w - TargetWidth) / 2
+		if isCenteredView && editor > TargetWidth+4 {
+			hPadding = (editor - TargetWidth) / 2
 		} else {
 			hPadding = 2 // Default small padding
 		}
@@ -366,36 +1001,56 @@ func main() {
 		analysisView.SetBorderPadding(1, 1, hPadding, hPadding)
 		wikiArea.SetBorderPadding(1, 1, 2, 2) // Wiki gets standard padding
 
+		// The status bar takes the room its goals need, the help line the rest
+		if currentView != ViewWiki {
+			mainView.SetColumns(0, min(tview.TaggedStringWidth(position.GetText(false))+1, w*2/3))
+		}
+
 		return false
 	})
 
 	saveCurrentChapter := func() {
-		if currentChapterIndex >= 0 && currentChapterIndex < len(chapters) {
-			chapters[currentChapterIndex].Content = textArea.GetText()
-			chapters[currentChapterIndex].Notes = notesArea.GetText()
+		if book.CurrentChapter >= 0 && book.CurrentChapter < len(book.Chapters) {
+			book.Chapters[book.CurrentChapter].Content = textArea.GetText()
+			book.Chapters[book.CurrentChapter].Notes = notesArea.GetText()
 		}
 	}
 
 	saveCurrentWiki := func() {
-		if len(wikiEntries) > 0 && currentWikiIndex < len(wikiEntries) {
-			wikiEntries[currentWikiIndex].Content = wikiArea.GetText()
+		if len(book.Wiki) > 0 && book.CurrentWiki < len(book.Wiki) {
+			book.Wiki[book.CurrentWiki].Content = wikiArea.GetText()
 		}
 	}
 
-	loadChapter := func(index int) {
-		saveCurrentChapter()
-		currentChapterIndex = index
-		chapter := chapters[index]
-
-		textArea.SetText(chapter.Content, false)
-		notesArea.SetText(chapter.Notes, false)
+	// discardJournal drops the recovery journal once nothing is unsaved
+	discardJournal := func() {
+		if journalPath != "" {
+			journaler.Remove(journalPath)
+			journalPath = ""
+		}
+		journalSum = ""
+	}
 
-		title := fmt.Sprintf("gowrite - Chapter %d: %s", index+1, chapter.Title)
+	// titleChapter names the current chapter, by number, on the editors
+	titleChapter := func() {
+		title := fmt.Sprintf("gowrite - Chapter %d: %s", book.CurrentChapter+1, book.Chapter().Title)
 		if currentView == ViewNotes {
 			title += " (NOTES)"
 		}
 		textArea.SetTitle(title)
-		notesArea.SetTitle(fmt.Sprintf("NOTES - Chapter %d", index+1))
+		notesArea.SetTitle(fmt.Sprintf("NOTES - Chapter %d", book.CurrentChapter+1))
+	}
+
+	// showChapter puts a chapter into the editors without saving the old one
+	showChapter := func(index int) {
+		book.CurrentChapter = index
+		chapter := book.Chapters[index]
+
+		otherWords = -1
+		showInEditor(textArea, session, chapter)
+		notesArea.SetText(chapter.Notes, false)
+
+		titleChapter()
 
 		pages.HidePage("modal")
 
@@ -406,24 +1061,29 @@ func main() {
 		}
 	}
 
+	loadChapter := func(index int) {
+		saveCurrentChapter()
+		showChapter(index)
+	}
+
 	// Forward declaration for recursion
 	var loadWiki func(int)
 
-	loadWiki = func(index int) {
-		saveCurrentWiki()
-		if index < 0 || index >= len(wikiEntries) {
+	// showWiki puts a wiki entry into the editor without saving the old one
+	showWiki := func(index int) {
+		if index < 0 || index >= len(book.Wiki) {
 			return
 		}
-		currentWikiIndex = index
-		entry := wikiEntries[index]
+		book.CurrentWiki = index
+		entry := book.Wiki[index]
 
 		wikiArea.SetText(entry.Content, false)
 		wikiArea.SetTitle(fmt.Sprintf("Wiki: %s", entry.Title))
 
 		wikiList.Clear()
-		for i, w := range wikiEntries {
+		for i, w := range book.Wiki {
 			title := w.Title
-			if i == currentWikiIndex {
+			if i == book.CurrentWiki {
 				title += " *"
 			}
 			idx := i
@@ -432,13 +1092,41 @@ func main() {
 				app.SetFocus(wikiArea)
 			})
 		}
-		wikiList.SetCurrentItem(currentWikiIndex)
+		wikiList.SetCurrentItem(book.CurrentWiki)
 	}
 
-	setView := func(viewType int) {
-		if currentView == ViewWiki {
-			saveCurrentWiki()
-		} else {
+	loadWiki = func(index int) {
+		saveCurrentWiki()
+		showWiki(index)
+	}
+
+	// The row of the script pane each element of the chapter starts on
+	var scriptStarts []int
+
+	// followScript scrolls the script pane to the element at the cursor,
+	// if it is out of sight
+	followScript := func() {
+		text := textArea.GetText()
+		_, cursor, _ := textArea.GetSelection()
+		row := scriptRow(text, cursor, scriptStarts)
+		offset, _ := scriptView.GetScrollOffset()
+		if _, _, _, height := scriptView.GetInnerRect(); row < offset || row >= offset+height {
+			scriptView.ScrollTo(max(row-height/3, 0), 0)
+		}
+	}
+
+	// showScript lays the chapter in the editor out in the script pane
+	showScript := func() {
+		var script string
+		script, scriptStarts = layoutScreenplay(textArea.GetText())
+		scriptView.SetText(script)
+		followScript()
+	}
+
+	setView := func(viewType int) {
+		if currentView == ViewWiki {
+			saveCurrentWiki()
+		} else {
 			saveCurrentChapter()
 		}
 
@@ -447,18 +1135,22 @@ func main() {
 
 		var activeWidget tview.Primitive
 		var title string
-		chapter := chapters[currentChapterIndex]
+		chapter := book.Chapters[book.CurrentChapter]
 
 		switch viewType {
 		case ViewMain:
 			activeWidget = textArea
-			title = fmt.Sprintf("gowrite - Chapter %d: %s", currentChapterIndex+1, chapter.Title)
+			title = fmt.Sprintf("gowrite - Chapter %d: %s", book.CurrentChapter+1, chapter.Title)
 			helpInfo.SetText(defaultHelpText)
 			mainView.SetColumns(0) // Reset to single column
+			if book.Mode == project.ModeScreenplay {
+				activeWidget = scriptPane
+				showScript()
+			}
 
 		case ViewNotes:
 			activeWidget = notesArea
-			title = fmt.Sprintf("gowrite - Chapter %d: %s (NOTES)", currentChapterIndex+1, chapter.Title)
+			title = fmt.Sprintf("gowrite - Chapter %d: %s (NOTES)", book.CurrentChapter+1, chapter.Title)
 			helpInfo.SetText(" EDITING NOTES | Ctrl-N: Back | Ctrl-T: Center | Ctrl-F: Focus Mode")
 			mainView.SetColumns(0) // Reset to single column
 
@@ -468,13 +1160,19 @@ func main() {
 			helpInfo.SetText(" ANALYSIS | [Blue]Adverbs [Green]Passive [Yellow]Hard [Red]Very Hard | Esc: Exit")
 			mainView.SetColumns(0) // Reset to single column
 
+		case ViewScript:
+			activeWidget = analysisView
+			title = fmt.Sprintf("SCREENPLAY PREVIEW - %d: %s", book.CurrentChapter+1, chapter.Title)
+			helpInfo.SetText(" PREVIEW | Ctrl-P or Esc: Back to the script")
+			mainView.SetColumns(0)
+
 		case ViewWiki:
 			// WIKI LAYOUT: List on left, Text on right
 			activeWidget = wikiList
 			title = "Story Wiki"
 			helpInfo.SetText(" Wiki | Enter: Select | Tab: Edit Text | Ctrl-W: Close | 'wiki new/del' to manage")
 
-			loadWiki(currentWikiIndex)
+			loadWiki(book.CurrentWiki)
 
 			mainView.SetColumns(30, 0)
 			mainView.SetRows(0, 3, 1)
@@ -496,6 +1194,7 @@ func main() {
 				wikiArea.SetBorder(true)
 			}
 
+			updateInfos()
 			app.SetFocus(wikiList)
 			return // Exit function early, we handled the layout manually
 		}
@@ -505,6 +1204,11 @@ func main() {
 			// FOCUS: Single row, no borders, full height
 			mainView.SetRows(0)
 			mainView.AddItem(activeWidget, 0, 0, 1, 2, 0, 0, true)
+			// ...but for the status bar while a sprint counts down
+			if sprint != nil {
+				mainView.SetRows(0, 1)
+				mainView.AddItem(position, 1, 0, 1, 2, 0, 0, false)
+			}
 
 			if v, ok := activeWidget.(*tview.TextArea); ok {
 				v.SetBorder(false)
@@ -528,7 +1232,14 @@ func main() {
 			}
 		}
 
-		// 4. Focus
+		// The editor in the script pane has the view's border and title
+		if activeWidget == tview.Primitive(scriptPane) {
+			textArea.SetBorder(!isFocusMode).SetTitle(title)
+			scriptView.SetBorder(!isFocusMode)
+		}
+
+		// 4. Status bar and focus
+		updateInfos()
 		app.SetFocus(activeWidget)
 	}
 
@@ -548,8 +1259,11 @@ func main() {
 		}
 	}
 
+	// toggleFocus turns focus mode on or off. Toggled by hand, focus mode
+	// is the writer's own, and stays as it is when a sprint ends.
 	toggleFocus := func() {
 		isFocusMode = !isFocusMode
+		sprintFocus = false
 		setView(currentView)
 	}
 
@@ -562,7 +1276,7 @@ func main() {
 			// Restore focus
 			if currentView == ViewNotes {
 				app.SetFocus(notesArea)
-			} else if currentView == ViewAnalyze {
+			} else if currentView == ViewAnalyze || currentView == ViewScript {
 				app.SetFocus(analysisView)
 			} else if currentView == ViewWiki {
 				app.SetFocus(wikiArea)
@@ -632,33 +1346,34 @@ func main() {
 
 	// --- CHAPTER OPS ---
 	deleteChapter := func(index int) {
-		if len(chapters) <= 1 {
+		if len(book.Chapters) <= 1 {
 			showModal("Error", "Cannot delete only chapter.")
 			return
 		}
-		if index < 0 || index >= len(chapters) {
+		if index < 0 || index >= len(book.Chapters) {
 			showModal("Error", "Invalid chapter.")
 			return
 		}
 
 		showYesNoModal("Confirm", fmt.Sprintf("Delete Chapter %d?", index+1), func() {
-			chapters = append(chapters[:index], chapters[index+1:]...)
-			if index < currentChapterIndex {
-				currentChapterIndex--
-			} else if index == currentChapterIndex && currentChapterIndex >= len(chapters) {
-				currentChapterIndex = len(chapters) - 1
+			// Save before deleting: afterwards the editor text would land in
+			// whichever chapter slid into this slot
+			saveCurrentChapter()
+			if err := book.DeleteChapter(index); err != nil {
+				showModal("Error", err.Error())
+				return
 			}
-			loadChapter(currentChapterIndex)
+			showChapter(book.CurrentChapter)
 		})
 	}
 
 	renameChapter := func(index int, newName string) {
-		if index < 0 || index >= len(chapters) {
+		if err := book.RenameChapter(index, newName); err != nil {
+			showModal("Error", err.Error())
 			return
 		}
-		chapters[index].Title = newName
-		if index == currentChapterIndex {
-			loadChapter(currentChapterIndex)
+		if index == book.CurrentChapter {
+			loadChapter(book.CurrentChapter)
 		} else {
 			showModal("Success", fmt.Sprintf("Renamed Chapter %d to '%s'", index+1, newName))
 		}
@@ -666,113 +1381,41 @@ func main() {
 
 	// --- STRUCTURE TEMPLATES ---
 	applyStructure := func(name string) {
-		var newChapters []Chapter
 		name = strings.ToLower(name)
-
-		switch name {
-		case "3act", "standard":
-			newChapters = []Chapter{
-				{Title: "Act 1: The Setup", Notes: "Introduce characters and the ordinary world.\nEstablish the status quo and the flaw that holds them back.", Content: ">> GUIDANCE: Introduce the protagonist in their 'Ordinary World'. Establish the status quo and the flaw that holds them back."},
-				{Title: "Inciting Incident", Notes: "Something happens that disrupts the status quo.\nThe hero faces a problem they cannot ignore.", Content: ">> GUIDANCE: An external event disrupts the status quo. The hero faces a problem they cannot ignore."},
-				{Title: "Plot Point 1", Notes: "The hero leaves the ordinary world.\nThe hero decides to engage with the problem.", Content: ">> GUIDANCE: The hero decides to engage with the problem. They leave their comfort zone and cross into the 'Special World'."},
-				{Title: "Act 2: The Confrontation", Notes: "Rising action, tests, allies, and enemies.", Content: ">> GUIDANCE: Rising action. The hero meets allies and enemies. They face tests that force them to learn new skills."},
-				{Title: "Midpoint", Notes: "A major event shifts the context (false victory/defeat).\nThe stakes are raised; there is no turning back.", Content: ">> GUIDANCE: A major event shifts the context (a false victory or defeat). The stakes are raised; there is no turning back."},
-				{Title: "Plot Point 2", Notes: "All hope seems lost (The Dark Night of the Soul).\nThe hero must find a new solution or inner strength.", Content: ">> GUIDANCE: All hope seems lost. The hero must find a new solution or inner strength."},
-				{Title: "Act 3: The Resolution", Notes: "The final battle/climax.\nThe hero faces the antagonist one last time.", Content: ">> GUIDANCE: The Climax. The hero faces the antagonist one last time. They must use the lessons learned in Act 2 to win."},
-				{Title: "The End", Notes: "The aftermath. Establish the 'New Normal'.\nShow how the hero has changed.", Content: ">> GUIDANCE: The aftermath. Establish the 'New Normal'. Show how the hero has changed."},
-			}
-		case "hero", "monomyth":
-			newChapters = []Chapter{
-				{Title: "The Ordinary World", Notes: "Status Quo.", Content: ">> GUIDANCE: Show the hero's life before the journey. Highlight their dissatisfaction or lack of completeness."},
-				{Title: "Call to Adventure", Notes: "Disruption.", Content: ">> GUIDANCE: Something shakes up the situation. The hero is presented with a challenge or opportunity."},
-				{Title: "Refusal of the Call", Notes: "Fear or hesitation.", Content: ">> GUIDANCE: The hero hesitates due to fear or insecurity. Why are they afraid to leave?"},
-				{Title: "Meeting the Mentor", Notes: "Gaining tools/advice.", Content: ">> GUIDANCE: The hero gains supplies, advice, or confidence from a mentor. They are now ready to face the journey."},
-				{Title: "Crossing the Threshold", Notes: "Leaving the known world.", Content: ">> GUIDANCE: The hero commits to leaving the Ordinary World. They enter the Special World with different rules."},
-				{Title: "Tests, Allies, Enemies", Notes: "Learning the rules.", Content: ">> GUIDANCE: The hero explores the new world. They make friends and attract enemies."},
-				{Title: "Approach to the Cave", Notes: "Preparing for the main danger.", Content: ">> GUIDANCE: The hero prepares for the major challenge. Plans are made, and the team is gathered."},
-				{Title: "The Ordeal", Notes: "Death and rebirth moment.", Content: ">> GUIDANCE: The central crisis (midpoint). A brush with death. The hero confronts their greatest fear."},
-				{Title: "The Reward", Notes: "Seizing the sword.", Content: ">> GUIDANCE: The hero seizes the object of their quest (sword, elixir, knowledge). But the danger is not over yet."},
-				{Title: "The Road Back", Notes: "The chase scene/urgency.", Content: ">> GUIDANCE: The hero is pursued by the vengeful forces. The urgency ramps up for the final escape."},
-				{Title: "Resurrection", Notes: "Final test.", Content: ">> GUIDANCE: The final test. The hero is purified by a last sacrifice. They must prove they have truly learned the lesson."},
-				{Title: "Return with Elixir", Notes: "Master of two worlds.", Content: ">> GUIDANCE: The hero returns home, transformed. They bring back something that heals the Ordinary World."},
-			}
-		case "cat", "save the cat":
-			newChapters = []Chapter{
-				{Title: "Opening Image", Notes: "Snapshot of life before.", Content: ">> GUIDANCE: A visual snapshot of the status quo. Set the tone and mood."},
-				{Title: "Theme Stated", Notes: "What the story is really about.", Content: ">> GUIDANCE: Someone (usually not the hero) states the theme of the story. The hero doesn't understand it yet."},
-				{Title: "Setup", Notes: "Expanding on the hero's flaws.", Content: ">> GUIDANCE: Expand on the hero's life and flaws. Show why they need to change (Stasis = Death)."},
-				{Title: "Catalyst", Notes: "Life changes forever.", Content: ">> GUIDANCE: The Inciting Incident. Life changes forever; they can't go back."},
-				{Title: "Debate", Notes: "Can I do this?", Content: ">> GUIDANCE: The hero reacts to the catalyst. They question what to do (Refusal of the Call)."},
-				{Title: "Break into Two", Notes: "Choosing the journey.", Content: ">> GUIDANCE: The hero makes a proactive choice to enter the new world. Act 2 begins."},
-				{Title: "B Story", Notes: "Love interest or subplot.", Content: ">> GUIDANCE: Introduce the love interest or subplot character. This relationship discusses the theme."},
-				{Title: "Fun and Games", Notes: "The 'trailer' moments.", Content: ">> GUIDANCE: The 'Promise of the Premise'. Show scenes that audiences came to see."},
-				{Title: "Midpoint", Notes: "Stakes raise significantly.", Content: ">> GUIDANCE: Stakes raise significantly (False Victory or False Defeat). The 'clock' starts ticking."},
-				{Title: "Bad Guys Close In", Notes: "Pressure mounts.", Content: ">> GUIDANCE: Internal and external pressure mounts. The hero's plan starts to fail."},
-				{Title: "All Is Lost", Notes: "Whiff of death.", Content: ">> GUIDANCE: The lowest point. Something dies (literally or metaphorically). The hero loses hope."},
-				{Title: "Dark Night of the Soul", Notes: "Wallowing in hopelessness.", Content: ">> GUIDANCE: The hero wallows in their hopelessness. But in the darkness, they find the true solution."},
-				{Title: "Break into Three", Notes: "The new idea/solution.", Content: ">> GUIDANCE: The hero realizes the answer (fixing the flaw). They devise a new plan."},
-				{Title: "Finale", Notes: "Executing the plan.", Content: ">> GUIDANCE: The hero executes the plan and defeats the bad guys. The old world is destroyed/changed."},
-				{Title: "Final Image", Notes: "Mirror of opening image.", Content: ">> GUIDANCE: Mirror of the Opening Image. Show visually how much the hero has changed."},
-			}
-		case "fichtean":
-			newChapters = []Chapter{
-				{Title: "Inciting Incident", Notes: "Start immediately with the problem.", Content: ">> GUIDANCE: Skip the setup. Start immediately with the problem. Throw the reader into the action."},
-				{Title: "Crisis 1", Notes: "First obstacle. Rising action.", Content: ">> GUIDANCE: The first major obstacle. The hero tries to solve it but complications arise."},
-				{Title: "Crisis 2", Notes: "Higher stakes obstacle.", Content: ">> GUIDANCE: The stakes get higher. The problem expands or gets more personal."},
-				{Title: "Crisis 3", Notes: "Even higher stakes.", Content: ">> GUIDANCE: The situation seems dire. The hero's resources are running thin."},
-				{Title: "The Climax", Notes: "Maximum tension.", Content: ">> GUIDANCE: Maximum tension. The final confrontation. The hero succeeds or fails."},
-				{Title: "Falling Action", Notes: "Loose ends tied.", Content: ">> GUIDANCE: Loose ends are tied up. The immediate aftermath of the climax."},
-				{Title: "Resolution", Notes: "New normal.", Content: ">> GUIDANCE: The new normal is established. A brief moment of calm."},
-			}
-		case "horror":
-			newChapters = []Chapter{
-				{Title: "The Dreadful Normal", Notes: "Establish status quo with unease.", Content: ">> GUIDANCE: Establish the setting and characters. Create a subtle sense of unease or isolation despite the normalcy."},
-				{Title: "The Omen", Notes: "A warning sign.", Content: ">> GUIDANCE: A warning sign appears but is ignored or rationalized. The first subtle brush with the entity."},
-				{Title: "The Onset", Notes: "The threat reveals itself.", Content: ">> GUIDANCE: The threat reveals itself properly. The first scare or victim. There is no going back now."},
-				{Title: "The Discovery", Notes: "Realization of the horror.", Content: ">> GUIDANCE: The characters realize what they are dealing with. Escape attempts fail. Isolation is complete."},
-				{Title: "The Pursuit", Notes: "Cat and Mouse.", Content: ">> GUIDANCE: The entity attacks. High tension chase or siege. The characters are stripped of resources."},
-				{Title: "The Confrontation", Notes: "The final stand.", Content: ">> GUIDANCE: The final stand. The remaining survivors must face the horror head-on. High casualty rate."},
-				{Title: "The Aftermath", Notes: "Survival... or is it?", Content: ">> GUIDANCE: The evil is defeated... or is it? The survivors escape, but they are changed forever."},
-			}
-		default:
+		if _, err := project.Structure(name); err != nil {
 			showModal("Error", "Unknown structure.\nTry: 3act, hero, cat, fichtean, horror")
 			return
 		}
 
 		showYesNoModal("Warning", fmt.Sprintf("This will ERASE all current chapters and apply '%s'. Continue?", name), func() {
-			chapters = newChapters
-			currentChapterIndex = 0
-			// FIX: Manually update UI to avoid 'loadChapter' saving old blank text over new template
-			textArea.SetText(chapters[0].Content, false)
-			notesArea.SetText(chapters[0].Notes, false)
-			textArea.SetTitle(fmt.Sprintf("gowrite - Chapter 1: %s", chapters[0].Title))
+			book.ApplyStructure(name)
+			// showChapter, not loadChapter: saving would write the old text over the template
+			showChapter(0)
 			flashStatusMessage("Applied Structure: " + name)
 		})
 	}
 
 	// --- WIKI OPS ---
 	deleteWiki := func(index int) {
-		if len(wikiEntries) <= 1 {
+		if len(book.Wiki) <= 1 {
 			showModal("Error", "Cannot delete the only wiki entry.")
 			return
 		}
-		showYesNoModal("Confirm", fmt.Sprintf("Delete Wiki Entry '%s'?", wikiEntries[index].Title), func() {
-			wikiEntries = append(wikiEntries[:index], wikiEntries[index+1:]...)
-			if index < currentWikiIndex {
-				currentWikiIndex--
-			} else if index == currentWikiIndex && currentWikiIndex >= len(wikiEntries) {
-				currentWikiIndex = len(wikiEntries) - 1
+		showYesNoModal("Confirm", fmt.Sprintf("Delete Wiki Entry '%s'?", book.Wiki[index].Title), func() {
+			saveCurrentWiki()
+			if err := book.DeleteWiki(index); err != nil {
+				showModal("Error", err.Error())
+				return
 			}
-			loadWiki(currentWikiIndex)
+			showWiki(book.CurrentWiki)
 		})
 	}
 
 	renameWiki := func(index int, newName string) {
-		if index < 0 || index >= len(wikiEntries) {
+		if err := book.RenameWiki(index, newName); err != nil {
 			return
 		}
-		wikiEntries[index].Title = newName
-		loadWiki(currentWikiIndex)
+		loadWiki(book.CurrentWiki)
 	}
 
 	// --- ANALYSIS LOGIC (Hemingway) ---
@@ -793,27 +1436,889 @@ func main() {
 		showModal("Readability Report", stats+key)
 	}
 
-	// --- SPELL CHECK ---
-	loadDictionary := func() error {
-		file, err := os.Open("dictionary.txt")
+	// --- SCREENPLAY ---
+
+	// previewScript shows the current chapter laid out as a screenplay
+	previewScript := func() {
+		if book.Mode != project.ModeScreenplay {
+			showModal("Preview", "Preview lays out a screenplay. Turn screenplay mode on first: 'screenplay on'")
+			return
+		}
+		saveCurrentChapter()
+		analysisView.SetText(RenderScreenplay(book.Chapters[book.CurrentChapter].Content))
+		analysisView.ScrollToBeginning()
+		setView(ViewScript)
+	}
+
+	// --- IMPORT ---
+
+	// importChapters reads a manuscript, document or Scrivener project off
+	// the UI goroutine and adds it as chapters, split at its headings
+	importChapters := func(path string, opt importer.Options) {
+		flashStatusMessage("Importing file...")
+
+		go func() {
+			chapters, wiki, err := importer.Read(path, opt)
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					showModal("Error", fmt.Sprintf("Failed to import file: %v", err))
+					return
+				}
+				if len(chapters) == 0 && len(wiki) == 0 {
+					showModal("Error", fmt.Sprintf("Nothing to import in %s", path))
+					return
+				}
+
+				// Save first: a blank starting chapter or wiki entry is
+				// replaced, so the editors must not write back over it
+				saveCurrentChapter()
+				saveCurrentWiki()
+				msg := fmt.Sprintf("Imported %s as %s", path, plural(len(chapters), "chapter", "chapters"))
+				if len(wiki) > 0 {
+					showWiki(book.ImportWiki(wiki))
+					msg += " and " + plural(len(wiki), "wiki entry", "wiki entries")
+				}
+				if len(chapters) > 0 {
+					showChapter(book.ImportChapters(chapters))
+				}
+				flashStatusMessage(msg)
+			})
+		}()
+	}
+
+	// --- SEARCH ---
+
+	// The last search, which F3 and Shift-F3 repeat, and the search bar: the
+	// command palette taking a term, matched as it is typed
+	var (
+		searchTerm    string
+		searchOptions search.Options
+		searchBar     bool
+		searchFrom    int // the cursor when the search bar opened
+	)
+
+	// editorArea is the text area searched in the current view, nil where
+	// there is nothing to search
+	editorArea := func() *tview.TextArea {
+		switch currentView {
+		case ViewMain:
+			return textArea
+		case ViewNotes:
+			return notesArea
+		case ViewWiki:
+			return wikiArea
+		}
+		return nil
+	}
+
+	// selectMatch selects text[start:end] of area, scrolling it into view
+	selectMatch := func(area *tview.TextArea, start, end int) {
+		area.Select(start, end)
+		row, _, _, _ := area.GetCursor()
+		_, _, _, height := area.GetInnerRect()
+		if offset, _ := area.GetOffset(); row < offset || row >= offset+height {
+			area.SetOffset(max(row-height/3, 0), 0)
+		}
+	}
+
+	// findMatch selects the match of the last search after the selection,
+	// or before it, and says which it is. from, when not negative, is where
+	// to look forward from instead.
+	findMatch := func(forward bool, from int) string {
+		area := editorArea()
+		if area == nil {
+			return "Search works in the chapter, notes and wiki"
+		}
+		m, err := search.Compile(searchTerm, searchOptions)
+		if err != nil {
+			return err.Error()
+		}
+		matches := m.All(area.GetText())
+		_, start, end := area.GetSelection()
+		var i int
+		switch {
+		case from >= 0:
+			i = search.Next(matches, from)
+		case forward:
+			i = search.Next(matches, end)
+		default:
+			i = search.Prev(matches, start)
+		}
+		if i < 0 {
+			return fmt.Sprintf("No match for '%s'", searchTerm)
+		}
+		selectMatch(area, matches[i][0], matches[i][1])
+		return fmt.Sprintf("Match %d of %d", i+1, len(matches))
+	}
+
+	searchTitle := func(status string) string {
+		title := "Search"
+		if on := searchOptions.String(); on != "" {
+			title += " (" + on + ")"
+		}
+		if status != "" {
+			title += ": " + status
+		}
+		return title
+	}
+
+	openSearchBar := func() {
+		area := editorArea()
+		if area == nil {
+			showModal("Search", "Search works in the chapter, notes and wiki.")
+			return
+		}
+		_, searchFrom, _ = area.GetSelection()
+		searchBar = true
+		commandPalette.SetLabel(" / ").SetPlaceholder("Alt-C match case, Alt-W whole word, Alt-R regex, Enter done, Esc cancel")
+		commandPalette.SetTitle(searchTitle(""))
+		app.SetFocus(commandPalette)
+	}
+
+	closeSearchBar := func() {
+		searchBar = false
+		commandPalette.SetText("")
+		commandPalette.SetLabel(" > ").SetPlaceholder("Type 'help' for commands")
+		commandPalette.SetTitle("Command Palette")
+	}
+
+	// Every match in sight is marked while the search bar is open, and while
+	// F3 and Shift-F3 step through them: until the selection is let go
+	app.SetAfterDrawFunc(func(screen tcell.Screen) {
+		area := editorArea()
+		if area == nil || searchTerm == "" {
+			return
+		}
+		if page, _ := pages.GetFrontPage(); page != "main" {
+			return
+		}
+		if _, start, end := area.GetSelection(); !searchBar && start == end {
+			return
+		}
+		m, err := search.Compile(searchTerm, searchOptions)
+		if err != nil {
+			return
+		}
+		markMatches(screen, area, m, area.GetTextStyle().Background(tview.Styles.ContrastBackgroundColor))
+	})
+
+	// --- TARGETS ---
+
+	// The chapter whose goal the status bar last showed, and whether it was
+	// met, so that reaching it is announced once
+	goalChapter, goalMet := -1, false
+
+	// bookWords returns the words in the manuscript, given those in the
+	// editor's chapter. The other chapters are counted once, not on every
+	// keystroke.
+	bookWords := func(current int) int {
+		if otherWords < 0 {
+			otherWords = 0
+			for i, c := range book.Chapters {
+				if i != book.CurrentChapter {
+					otherWords += CountText(c.Content).Words
+				}
+			}
+		}
+		return otherWords + current
+	}
+
+	// The words a day the deadline asks for, today. They change only from
+	// one day to the next, so the plan is made once a minute and when the
+	// project or its deadline changes, not with every update of the status.
+	deadlineDaily := 0
+	planDeadline := func() {
+		deadlineDaily = 0
+		if plan, err := book.Plan(history, bookWords(CountText(textArea.GetText()).Words)); err == nil {
+			deadlineDaily = plan.PerDay
+		}
+	}
+	go every(time.Minute, queueUpdate, func() {
+		planDeadline()
+		updateInfos()
+	}, stop)
+
+	// goalStatus shows the chapter's words against its target, with a
+	// progress bar, and the manuscript's, for the status bar. A met goal
+	// turns green, and reaching it while writing is announced.
+	goalStatus := func(words int) string {
+		status := fmt.Sprintf("[%s]%d[white]", tview.Styles.SecondaryTextColor, words)
+		if target := book.Chapter().Target; target > 0 {
+			progress, met := goalProgress(words, target)
+			if met {
+				status = "[green]" + progress + " ✓[white]"
+			} else {
+				status = fmt.Sprintf("[%s]%s[white] %s", tview.Styles.SecondaryTextColor, progress, progressBar(words, target, 10))
+			}
+			if book.CurrentChapter == goalChapter && met && !goalMet {
+				flashStatusMessage(fmt.Sprintf(" Chapter goal of %d words reached! ", target))
+			}
+			goalChapter, goalMet = book.CurrentChapter, met
+		}
+		total := words
+		if book.Target > 0 {
+			total = bookWords(words)
+			progress, met := goalProgress(total, book.Target)
+			if met {
+				progress = "[green]" + progress + " ✓[white]"
+			}
+			status += " | Book: " + progress
+		}
+		// Without a daily goal of its own, a deadline sets one
+		daily := book.Daily
+		if daily == 0 {
+			daily = deadlineDaily
+		}
+		if daily > 0 {
+			progress, met := goalProgress(history.Today(), daily)
+			if met {
+				progress = "[green]" + progress + " ✓[white]"
+			}
+			status += fmt.Sprintf(" | Session: %+d | Today: %s", session.Words(), progress)
+		} else if len(session.Chapters) > 0 {
+			status += fmt.Sprintf(" | Session: %+d", session.Words())
+		}
+		return status
+	}
+
+	// setTarget handles 'target', 'target N' and 'target book N'
+	setTarget := func(args []string) {
+		if len(args) == 0 {
+			saveCurrentChapter()
+			chapter := "no target set ('target <words>')"
+			if target := book.Chapter().Target; target > 0 {
+				chapter, _ = goalProgress(CountText(book.Chapter().Content).Words, target)
+			}
+			manuscript := "no target set ('target book <words>')"
+			if book.Target > 0 {
+				total := 0
+				for _, c := range book.Chapters {
+					total += CountText(c.Content).Words
+				}
+				manuscript, _ = goalProgress(total, book.Target)
+			}
+			showModal("Targets", fmt.Sprintf("Chapter %d: %s\nManuscript: %s", book.CurrentChapter+1, chapter, manuscript))
+			return
+		}
+
+		whole := strings.ToLower(args[0]) == "book" || strings.ToLower(args[0]) == "manuscript"
+		if whole {
+			args = args[1:]
+		}
+		n, err := 0, error(nil)
+		if len(args) == 1 && strings.ToLower(args[0]) != "off" {
+			n, err = strconv.Atoi(args[0])
+		}
+		if len(args) != 1 || err != nil || n < 0 {
+			showModal("Error", "Usage: target <words> or target book <words> (0 or off to clear)")
+			return
+		}
+
+		if whole {
+			book.Target = n
+			planDeadline()
+			flashStatusMessage(fmt.Sprintf(" Manuscript target: %d words ", n))
+		} else {
+			book.Chapter().Target = n
+			// A goal already passed is not announced
+			goalChapter = -1
+			flashStatusMessage(fmt.Sprintf(" Chapter %d target: %d words ", book.CurrentChapter+1, n))
+		}
+		if n == 0 {
+			flashStatusMessage(" Target cleared ")
+		}
+		updateInfos()
+		if area := editorArea(); area != nil {
+			app.SetFocus(area)
+		} else {
+			app.SetFocus(textArea)
+		}
+	}
+
+	// setDailyGoal handles 'goal daily N'
+	setDailyGoal := func(args []string) {
+		n, err := 0, error(nil)
+		if len(args) == 2 && strings.ToLower(args[1]) != "off" {
+			n, err = strconv.Atoi(args[1])
+		}
+		if len(args) != 2 || strings.ToLower(args[0]) != "daily" || err != nil || n < 0 {
+			showModal("Error", "Usage: goal daily <words> (0 or off to clear)")
+			return
+		}
+		book.Daily = n
+		if n == 0 {
+			flashStatusMessage(" Daily goal cleared ")
+		} else {
+			flashStatusMessage(fmt.Sprintf(" Daily goal: %d words ", n))
+		}
+		updateInfos()
+		if area := editorArea(); area != nil {
+			app.SetFocus(area)
+		} else {
+			app.SetFocus(textArea)
+		}
+	}
+
+	// setDeadline handles 'deadline <date> [words]' and 'deadline off'
+	setDeadline := func(args []string) {
+		if len(args) == 1 && strings.ToLower(args[0]) == "off" {
+			book.Deadline = ""
+			planDeadline()
+			flashStatusMessage(" Deadline cleared ")
+		} else {
+			var date time.Time
+			var err error
+			n := book.Target
+			if len(args) == 1 || len(args) == 2 {
+				date, err = project.ParseDeadline(args[0])
+			}
+			if len(args) == 2 && err == nil {
+				n, err = strconv.Atoi(args[1])
+			}
+			if len(args) < 1 || len(args) > 2 || err != nil || n <= 0 {
+				showModal("Error", "Usage: deadline <yyyy-mm-dd> <words> (words may be left out once a book target is set), or deadline off")
+				return
+			}
+			if y, m, d := time.Now().Date(); date.Before(time.Date(y, m, d, 0, 0, 0, 0, time.Local)) {
+				showModal("Error", "That deadline has already passed.")
+				return
+			}
+			book.Deadline, book.Target = date.Format(project.DeadlineFormat), n
+			planDeadline()
+			flashStatusMessage(fmt.Sprintf(" %d words by %s: %d a day ", n, date.Format("2 Jan 2006"), deadlineDaily))
+		}
+		updateInfos()
+		if area := editorArea(); area != nil {
+			app.SetFocus(area)
+		} else {
+			app.SetFocus(textArea)
+		}
+	}
+
+	// showDeadline shows how the manuscript stands against its deadline
+	showDeadline := func() {
+		saveCurrentChapter()
+		words := 0
+		for _, c := range book.Chapters {
+			words += CountText(c.Content).Words
+		}
+		plan, err := book.Plan(history, words)
+		if err != nil {
+			showModal("Deadline", err.Error())
+			return
+		}
+		view := tview.NewTextView()
+		view.SetDynamicColors(true)
+		view.SetBorder(true)
+		view.SetTitle("Deadline (Esc to close)")
+		view.SetBorderPadding(1, 1, 2, 2)
+		view.SetText(deadlineReport(plan, book.Target))
+		view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyEnter {
+				pages.HidePage("modal")
+				if area := editorArea(); area != nil {
+					app.SetFocus(area)
+				} else {
+					app.SetFocus(textArea)
+				}
+				return nil
+			}
+			return event
+		})
+
+		grid := tview.NewGrid().SetColumns(0, 72, 0).SetRows(0, 13, 0).AddItem(view, 1, 1, 1, 1, 0, 0, true)
+		pages.AddPage("modal", grid, true, true)
+		app.SetFocus(view)
+	}
+
+	// showHistory lists the writing done day by day, the streak and pace
+	showHistory := func() {
+		view := tview.NewTextView()
+		view.SetDynamicColors(true)
+		view.SetBorder(true)
+		view.SetTitle("Writing History (Esc to close)")
+		view.SetBorderPadding(1, 1, 2, 2)
+		view.SetText(historyReport(history, session, book.Daily))
+		view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyEnter {
+				pages.HidePage("modal")
+				if area := editorArea(); area != nil {
+					app.SetFocus(area)
+				} else {
+					app.SetFocus(textArea)
+				}
+				return nil
+			}
+			return event
+		})
+
+		grid := tview.NewGrid().SetColumns(0, 64, 0).SetRows(-1, -6, -1).AddItem(view, 1, 1, 1, 1, 0, 0, true)
+		pages.AddPage("modal", grid, true, true)
+		app.SetFocus(view)
+	}
+
+	// --- SPRINT ---
+
+	// Closed to stop the running sprint's clock
+	var sprintStop chan struct{}
+
+	// sprintStatus shows the time left in the sprint and the words written
+	// so far, for the status bar
+	sprintStatus := func() string {
+		if sprint == nil {
+			return ""
+		}
+		left := time.Until(sprint.Start.Add(time.Duration(sprint.Minutes) * time.Minute))
+		return fmt.Sprintf("[yellow]Sprint %s[white] %+d | ", sprintClock(left), session.Words()-sprintBase)
+	}
+
+	// endSprint stops the sprint and logs it to the history. One that ran
+	// its time rings the bell.
+	endSprint := func() {
+		close(sprintStop)
+		sprint.End = time.Now()
+		sprint.Words = session.Words() - sprintBase
+		history.Sprints = append(history.Sprints, *sprint)
+		done := *sprint
+		sprint = nil
+
+		// Leave focus mode if the sprint brought it, or the clock's row
+		if sprintFocus {
+			toggleFocus()
+		} else if isFocusMode {
+			setView(currentView)
+		}
+		updateInfos()
+
+		if !done.Finished() {
+			flashStatusMessage(" " + sprintSummary(done) + " ")
+			return
+		}
+		if terminal != nil {
+			terminal.Beep()
+		}
+		showModal("Sprint over", sprintSummary(done))
+	}
+
+	// startSprint handles 'sprint <minutes> [focus]' and 'sprint stop'
+	startSprint := func(args []string) {
+		if len(args) == 1 && strings.ToLower(args[0]) == "stop" {
+			if sprint == nil {
+				showModal("Error", "No sprint is running.")
+				return
+			}
+			endSprint()
+			if area := editorArea(); area != nil {
+				app.SetFocus(area)
+			} else {
+				app.SetFocus(textArea)
+			}
+			return
+		}
+
+		minutes, err := 0, error(nil)
+		if len(args) > 0 {
+			minutes, err = strconv.Atoi(args[0])
+		}
+		focus := len(args) == 2 && strings.ToLower(args[1]) == "focus"
+		if len(args) == 0 || len(args) > 2 || (len(args) == 2 && !focus) || err != nil || minutes <= 0 {
+			showModal("Error", "Usage: sprint <minutes> [focus], or sprint stop")
+			return
+		}
+		if sprint != nil {
+			showModal("Error", "A sprint is already running ('sprint stop' ends it).")
+			return
+		}
+
+		running := &project.Sprint{Start: time.Now(), Minutes: minutes}
+		sprint, sprintBase = running, session.Words()
+		sprintStop = make(chan struct{})
+		end := running.Start.Add(time.Duration(minutes) * time.Minute)
+		go every(time.Second, queueUpdate, func() {
+			// A tick queued as the sprint stopped may run after it
+			if sprint != running {
+				return
+			}
+			if time.Now().Before(end) {
+				updateInfos()
+			} else {
+				endSprint()
+			}
+		}, sprintStop)
+
+		if focus && !isFocusMode {
+			toggleFocus()
+			sprintFocus = true
+		} else {
+			setView(currentView)
+		}
+	}
+
+	// --- CHAPTER MANAGER ---
+
+	// showChapterManager lists the chapters with their words and targets.
+	// Enter opens one; the other keys reorder, copy and delete them.
+	showChapterManager := func() {
+		saveCurrentChapter()
+		const title = "Chapters (< > move, m move to, c copy, d delete)"
+
+		list := tview.NewList()
+		list.ShowSecondaryText(false)
+		list.SetHighlightFullLine(true)
+		list.SetSelectedBackgroundColor(tview.Styles.TitleColor)
+		list.SetSelectedTextColor(tview.Styles.PrimitiveBackgroundColor)
+		list.SetBorder(true)
+		list.SetTitle(title)
+		list.SetBorderPadding(1, 1, 2, 2)
+
+		fill := func(selected int) {
+			otherWords = -1
+			list.Clear()
+			for i, chap := range book.Chapters {
+				idx := i
+				list.AddItem(chapterLine(i+1, chap, CountText(chap.Content).Words, i == book.CurrentChapter), "", 0, func() { loadChapter(idx) })
+			}
+			list.SetCurrentItem(selected)
+			// The editor's chapter may have changed number
+			titleChapter()
+		}
+		fill(book.CurrentChapter)
+
+		// 'm' and 'd' ask for a position or a yes first
+		prompt, typed := "", ""
+		list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			i := list.GetCurrentItem()
+			switch prompt {
+			case "move":
+				switch {
+				case event.Key() == tcell.KeyRune && event.Rune() >= '0' && event.Rune() <= '9':
+					typed += string(event.Rune())
+				case (event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2) && typed != "":
+					typed = typed[:len(typed)-1]
+				case event.Key() == tcell.KeyEnter:
+					n, _ := strconv.Atoi(typed)
+					if book.MoveChapter(i, n-1) == nil {
+						fill(n - 1)
+					}
+					prompt = ""
+				case event.Key() == tcell.KeyEscape:
+					prompt = ""
+				}
+				if prompt == "" {
+					list.SetTitle(title)
+				} else {
+					list.SetTitle(fmt.Sprintf("Move chapter %d to position (1-%d): %s", i+1, len(book.Chapters), typed))
+				}
+				return nil
+			case "delete":
+				if event.Key() == tcell.KeyRune && unicode.ToLower(event.Rune()) == 'y' {
+					wasCurrent := i == book.CurrentChapter
+					if err := book.DeleteChapter(i); err == nil {
+						if wasCurrent {
+							// The editor holds the deleted chapter's text
+							showChapter(book.CurrentChapter)
+							pages.ShowPage("modal")
+							app.SetFocus(list)
+						}
+						fill(min(i, len(book.Chapters)-1))
+					}
+				}
+				prompt = ""
+				list.SetTitle(title)
+				return nil
+			}
+
+			switch {
+			case event.Key() == tcell.KeyEscape:
+				pages.HidePage("modal")
+				app.SetFocus(textArea)
+				return nil
+			case event.Key() == tcell.KeyRune && event.Rune() == '<':
+				if book.MoveChapter(i, i-1) == nil {
+					fill(i - 1)
+				}
+				return nil
+			case event.Key() == tcell.KeyRune && event.Rune() == '>':
+				if book.MoveChapter(i, i+1) == nil {
+					fill(i + 1)
+				}
+				return nil
+			case event.Key() == tcell.KeyRune && event.Rune() == 'm':
+				prompt, typed = "move", ""
+				list.SetTitle(fmt.Sprintf("Move chapter %d to position (1-%d): ", i+1, len(book.Chapters)))
+				return nil
+			case event.Key() == tcell.KeyRune && event.Rune() == 'c':
+				if dup, err := book.DuplicateChapter(i); err == nil {
+					fill(dup)
+				}
+				return nil
+			case event.Key() == tcell.KeyDelete || event.Key() == tcell.KeyRune && event.Rune() == 'd':
+				if len(book.Chapters) <= 1 {
+					list.SetTitle("Cannot delete the only chapter")
+					return nil
+				}
+				prompt = "delete"
+				list.SetTitle(fmt.Sprintf("Delete chapter %d, %s? (y/n)", i+1, tview.Escape(book.Chapters[i].Title)))
+				return nil
+			}
+			return event
+		})
+
+		grid := tview.NewGrid().SetColumns(0, 62, 0).SetRows(0, 20, 0).AddItem(list, 1, 1, 1, 1, 0, 0, true)
+		pages.AddPage("modal", grid, true, true)
+		app.SetFocus(list)
+	}
+
+	// --- REPLACE ---
+
+	// lastReplace undoes the last project-wide replace
+	var lastReplace []search.Edit
+
+	// syncEditors shows texts that changed in book behind the editors'
+	// backs. Each is replaced whole, one step for the editor's own undo.
+	syncEditors := func() {
+		otherWords = -1
+		replaceInEditor(textArea, session, *book.Chapter())
+		for _, e := range []struct {
+			area *tview.TextArea
+			text string
+		}{
+			{notesArea, book.Chapter().Notes},
+			{wikiArea, book.WikiEntry().Content},
+		} {
+			if e.area.GetText() != e.text {
+				e.area.Replace(0, e.area.GetTextLength(), e.text)
+			}
+		}
+	}
+
+	// previewReplace lists every hit with what replaces it, each to be
+	// accepted or skipped, and hands the accepted ones to apply
+	previewReplace := func(m *search.Matcher, term, with string, hits []search.Hit, apply func([]search.Hit)) {
+		accepted := make([]bool, len(hits))
+		list := tview.NewList()
+		list.SetHighlightFullLine(true)
+		list.SetSelectedBackgroundColor(tview.Styles.TitleColor)
+		list.SetSelectedTextColor(tview.Styles.PrimitiveBackgroundColor)
+		list.SetBorder(true)
+		list.SetBorderPadding(1, 1, 2, 2)
+
+		count := func() int {
+			n := 0
+			for _, ok := range accepted {
+				if ok {
+					n++
+				}
+			}
+			return n
+		}
+		setItem := func(i int) {
+			mark := "[red]✗[-] skip"
+			if accepted[i] {
+				mark = "[green]✓[-] replace"
+			}
+			h := hits[i]
+			replacement := m.Expand(with, h.Text(book), h.Match)
+			list.SetItemText(i, fmt.Sprintf("%s  %s", mark, tview.Escape(hitLabel(book, h))),
+				fmt.Sprintf("   %s  →  %s", hitContext(h), tview.Escape(replacement)))
+			list.SetTitle(fmt.Sprintf("Replace '%s': %d of %d (Space skip, a all, n none, Enter apply)", tview.Escape(term), count(), len(hits)))
+		}
+		for i := range hits {
+			accepted[i] = true
+			list.AddItem("", "", 0, nil)
+			setItem(i)
+		}
+
+		closePreview := func() {
+			pages.HidePage("modal")
+			if area := editorArea(); area != nil {
+				app.SetFocus(area)
+			} else {
+				app.SetFocus(textArea)
+			}
+		}
+		list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			switch {
+			case event.Key() == tcell.KeyEscape:
+				closePreview()
+				flashStatusMessage(" Replace cancelled ")
+			case event.Key() == tcell.KeyEnter:
+				var chosen []search.Hit
+				for i, h := range hits {
+					if accepted[i] {
+						chosen = append(chosen, h)
+					}
+				}
+				closePreview()
+				if len(chosen) > 0 {
+					apply(chosen)
+				}
+			case event.Key() == tcell.KeyRune && event.Rune() == ' ':
+				i := list.GetCurrentItem()
+				accepted[i] = !accepted[i]
+				setItem(i)
+				if i+1 < len(hits) {
+					list.SetCurrentItem(i + 1)
+				}
+			case event.Key() == tcell.KeyRune && (event.Rune() == 'a' || event.Rune() == 'n'):
+				for i := range hits {
+					accepted[i] = event.Rune() == 'a'
+					setItem(i)
+				}
+			default:
+				return event
+			}
+			return nil
+		})
+
+		grid := tview.NewGrid().SetColumns(-1, -8, -1).SetRows(-1, -6, -1).AddItem(list, 1, 1, 1, 1, 0, 0, true)
+		pages.AddPage("modal", grid, true, true)
+		app.SetFocus(list)
+	}
+
+	// replaceText replaces term with with in the text being edited, or with
+	// all throughout the project, after a preview
+	replaceText := func(term, with string, opt search.Options, all bool) {
+		m, err := search.Compile(term, opt)
+		if err != nil {
+			showModal("Replace", err.Error())
+			return
+		}
+		area := editorArea()
+		if area == nil && !all {
+			showModal("Replace", "Replace works in the chapter, notes and wiki.")
+			return
+		}
+		saveCurrentChapter()
+		saveCurrentWiki()
+
+		var hits []search.Hit
+		if all {
+			hits = search.Find(book, m)
+		} else {
+			place := search.Place{Field: search.Content, Index: book.CurrentChapter}
+			if currentView == ViewNotes {
+				place.Field = search.Notes
+			} else if currentView == ViewWiki {
+				place = search.Place{Field: search.Wiki, Index: book.CurrentWiki}
+			}
+			hits = m.Hits(place, area.GetText())
+		}
+		if len(hits) == 0 {
+			showModal("Replace", fmt.Sprintf("No match for '%s'", term))
+			return
+		}
+
+		previewReplace(m, term, with, hits, func(chosen []search.Hit) {
+			if !all {
+				// One Replace of the whole text: a single Ctrl-Z undoes it
+				matches := make([][]int, len(chosen))
+				for i, h := range chosen {
+					matches[i] = h.Match
+				}
+				area.Replace(0, area.GetTextLength(), m.Replace(area.GetText(), with, matches))
+				area.Select(chosen[0].Match[0], chosen[0].Match[0])
+				flashStatusMessage(fmt.Sprintf(" Replaced %s ", plural(len(chosen), "match", "matches")))
+				return
+			}
+			lastReplace = search.ReplaceHits(book, m, with, chosen)
+			syncEditors()
+			flashStatusMessage(fmt.Sprintf(" Replaced %s in %s ", plural(len(chosen), "match", "matches"), plural(len(lastReplace), "place", "places")))
+		})
+	}
+
+	// undoReplace reverts the last project-wide replace as one step
+	undoReplace := func() {
+		if lastReplace == nil {
+			showModal("Replace", "There is no project-wide replace to undo.")
+			return
+		}
+		saveCurrentChapter()
+		saveCurrentWiki()
+		kept := search.Undo(book, lastReplace)
+		lastReplace = nil
+		syncEditors()
+		if kept > 0 {
+			showModal("Replace", fmt.Sprintf("Undid the replace, except in %s edited since.", plural(kept, "place", "places")))
+			return
+		}
+		flashStatusMessage(" Undid the replace ")
+	}
+
+	// --- FIND IN PROJECT ---
+
+	// goToHit opens the chapter, notes or wiki entry a hit is in and
+	// selects it
+	goToHit := func(h search.Hit) {
+		pages.HidePage("modal")
+		if view := hitView(h); view == ViewWiki {
+			setView(ViewWiki)
+			loadWiki(h.Index)
+		} else {
+			loadChapter(h.Index)
+			setView(view)
+		}
+		area := editorArea()
+		selectMatch(area, h.Match[0], h.Match[1])
+		app.SetFocus(area)
+	}
+
+	// findInProject lists every match of term in the chapters, notes and
+	// wiki. F3 carries on through the text a hit is opened in.
+	findInProject := func(term string, opt search.Options) {
+		m, err := search.Compile(term, opt)
 		if err != nil {
-			return err
+			showModal("Find", err.Error())
+			return
+		}
+		saveCurrentChapter()
+		saveCurrentWiki()
+		hits := search.Find(book, m)
+		if len(hits) == 0 {
+			showModal("Find", fmt.Sprintf("No match for '%s' in the project", term))
+			return
 		}
-		defer file.Close()
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			dictionary[strings.TrimSpace(strings.ToLower(scanner.Text()))] = true
+		searchTerm, searchOptions = term, opt
+
+		list := tview.NewList()
+		list.SetHighlightFullLine(true)
+		list.SetSelectedBackgroundColor(tview.Styles.TitleColor)
+		list.SetSelectedTextColor(tview.Styles.PrimitiveBackgroundColor)
+		list.SetBorder(true)
+		list.SetTitle(fmt.Sprintf("Find '%s': %s (Enter to go)", tview.Escape(term), plural(len(hits), "match", "matches")))
+		list.SetBorderPadding(1, 1, 2, 2)
+		for _, h := range hits {
+			hit := h // Capture for closure
+			list.AddItem(tview.Escape(hitLabel(book, hit)), "   "+hitContext(hit), 0, func() { goToHit(hit) })
 		}
-		dictionaryLoaded = true
-		return scanner.Err()
+
+		list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyEscape {
+				pages.HidePage("modal")
+				if area := editorArea(); area != nil {
+					app.SetFocus(area)
+				} else {
+					app.SetFocus(textArea)
+				}
+				return nil
+			}
+			return event
+		})
+
+		grid := tview.NewGrid().SetColumns(-1, -8, -1).SetRows(-1, -6, -1).AddItem(list, 1, 1, 1, 1, 0, 0, true)
+		pages.AddPage("modal", grid, true, true)
+		app.SetFocus(list)
 	}
 
+	// --- SPELL CHECK ---
 	runSpellCheck := func() {
-		if !dictionaryLoaded {
-			if err := loadDictionary(); err != nil {
+		if dictionary == nil {
+			d, err := LoadDictionary(DictionaryFile)
+			if err != nil {
 				showModal("Error", "Could not load 'dictionary.txt'.")
 				return
 			}
+			dictionary = d
 		}
 
 		targetArea := textArea
@@ -823,33 +2328,11 @@ func main() {
 			targetArea = wikiArea
 		}
 
-		text := targetArea.GetText()
-		words := strings.Fields(text)
-		unknowns := make(map[string]bool)
-
-		for _, rawWord := range words {
-			cleanWord := strings.TrimFunc(rawWord, func(r rune) bool {
-				return !unicode.IsLetter(r) && !unicode.IsNumber(r)
-			})
-			cleanWord = strings.ToLower(cleanWord)
-			if cleanWord == "" {
-				continue
-			}
-			if !dictionary[cleanWord] {
-				if strings.HasSuffix(cleanWord, "s") && dictionary[strings.TrimSuffix(cleanWord, "s")] {
-					continue
-				}
-				unknowns[cleanWord] = true
-			}
-		}
+		list := FindMisspellings(targetArea.GetText(), dictionary)
 
-		if len(unknowns) == 0 {
+		if len(list) == 0 {
 			showModal("Spell Check", "No misspellings found!")
 		} else {
-			var list []string
-			for w := range unknowns {
-				list = append(list, w)
-			}
 			displayLimit := 20
 			msg := "Potential misspellings:\n\n"
 			count := 0
@@ -866,148 +2349,265 @@ func main() {
 	}
 
 	// --- FILE IO ---
-	saveBook := func(filename string, silent bool) {
-		mu.Lock()
-		defer mu.Unlock()
+	// saveHistory writes out the writing sessions of a saved project
+	saveHistory := func() {
+		if book.Filename != "" && len(history.Sessions)+len(history.Sprints) > 0 {
+			project.SaveHistory(book.Filename, history)
+		}
+	}
 
+	saveBook := func(filename string, silent bool) {
 		saveCurrentChapter()
 		saveCurrentWiki() // Save Wiki entries too
 
-		if filename == "" {
-			if currentFilename == "" {
-				if !silent {
-					showModal("Error", "Please provide a filename: 'save <name>'")
-				}
-				return
-			}
-			filename = currentFilename
-		}
-		if !strings.HasSuffix(filename, ".json") {
-			filename += ".json"
-		}
-
-		// Create project struct to hold both Chapters and Wiki
-		projectData := Project{
-			Chapters: chapters,
-			Wiki:     wikiEntries,
-		}
-
-		data, err := json.MarshalIndent(projectData, "", "  ")
-		if err != nil {
-			if !silent {
-				showModal("Error", err.Error())
+		if err := book.Save(filename); err != nil {
+			if errors.Is(err, project.ErrNoFilename) {
+				err = errors.New("Please provide a filename: 'save <name>'")
 			}
-			return
-		}
-
-		err = os.WriteFile(filename, data, 0644)
-		if err != nil {
 			if !silent {
 				showModal("Error", err.Error())
 			}
 			return
 		}
+		savedSum = book.Fingerprint()
+		discardJournal()
+		saveHistory()
 
-		currentFilename = filename
 		if silent {
-			flashStatusMessage(fmt.Sprintf(" [Autosaved to %s at %s] ", filename, time.Now().Format("15:04:05")))
+			flashStatusMessage(fmt.Sprintf(" [Autosaved to %s at %s] ", book.Filename, time.Now().Format("15:04:05")))
 		} else {
-			showModal("Success", fmt.Sprintf("Saved to %s", filename))
+			showModal("Success", fmt.Sprintf("Saved to %s", book.Filename))
 		}
 	}
 
-	loadBook := func(filename string) {
-		mu.Lock()
-		defer mu.Unlock()
+	// editorFor returns the text area a view edits
+	editorFor := func(view int) *tview.TextArea {
+		switch view {
+		case ViewNotes:
+			return notesArea
+		case ViewWiki:
+			return wikiArea
+		}
+		return textArea
+	}
 
-		if !strings.HasSuffix(filename, ".json") {
-			filename += ".json"
+	// rememberState records where the user is in the current project so
+	// reopening it picks up from the same place
+	captureState := func() project.EditorState {
+		view := currentView
+		if _, ok := viewNames[view]; !ok {
+			view = ViewMain
+		}
+		area := editorFor(view)
+		row, col, _, _ := area.GetCursor()
+		_, offset, _ := area.GetSelection()
+		return project.EditorState{
+			Chapter:  book.CurrentChapter,
+			Wiki:     book.CurrentWiki,
+			View:     viewNames[view],
+			Row:      row,
+			Column:   col,
+			Offset:   offset,
+			Centered: isCenteredView,
+			Focus:    isFocusMode,
+			Theme:    currentTheme,
 		}
-		data, err := os.ReadFile(filename)
-		if err != nil {
-			showModal("Error", err.Error())
-			return
+	}
+
+	rememberState := func() {
+		if book.Filename != "" {
+			project.SaveState(book.Filename, captureState())
 		}
+		saveHistory()
+	}
 
-		// Try loading as Project struct (New format)
-		var projectData Project
-		err = json.Unmarshal(data, &projectData)
+	// writeJournal records unsaved edits in the recovery journal
+	writeJournal := func() {
+		saveCurrentChapter()
+		saveCurrentWiki()
 
-		validLoad := false
+		sum := book.Fingerprint()
+		if sum == savedSum {
+			discardJournal() // edits were undone
+			return
+		}
+		path := project.JournalPath(book.Filename, os.Getpid())
+		if sum == journalSum && path == journalPath {
+			return
+		}
+		if journalPath != "" && journalPath != path {
+			// The project was renamed by 'save <name>'
+			journaler.Remove(journalPath)
+		}
+		journaler.Write(&project.Journal{
+			Filename: book.Filename,
+			PID:      os.Getpid(),
+			Updated:  time.Now(),
+			State:    captureState(),
+			Project:  book.Clone(),
+		}, path)
+		journalPath = path
+		journalSum = sum
+	}
 
-		if err == nil && len(projectData.Chapters) > 0 {
-			// Success: It's the new format
-			chapters = projectData.Chapters
-			wikiEntries = projectData.Wiki
-			validLoad = true
-		} else {
-			// Failure: Try loading as old format (Just Array of Chapters)
-			var oldChapters []Chapter
-			if err2 := json.Unmarshal(data, &oldChapters); err2 == nil && len(oldChapters) > 0 {
-				chapters = oldChapters
-				wikiEntries = []WikiEntry{{Title: "General", Content: ""}} // Default wiki
-				validLoad = true
+	// showProject fills the editor from book, placing the user where st says
+	showProject := func(st project.EditorState) {
+		if sprint != nil {
+			endSprint()
+		}
+		// A project brings its own history, and a new session starts with it
+		history = &project.History{}
+		if book.Filename != "" {
+			if h, err := project.LoadHistory(book.Filename); err == nil {
+				history = h
 			}
 		}
+		session = &project.Session{}
+		if st.Theme != "" {
+			applyTheme(st.Theme)
+		}
+		isCenteredView = st.Centered
+		isFocusMode = st.Focus
+		if st.Chapter < 0 || st.Chapter >= len(book.Chapters) {
+			st.Chapter = 0
+		}
+		if st.Wiki < 0 || st.Wiki >= len(book.Wiki) {
+			st.Wiki = 0
+		}
+		restoreView, _ := parseView(st.View)
 
-		if !validLoad {
-			showModal("Error", "File empty or corrupt.")
-			return
+		// Fill the editors first: setView saves them back into the project
+		currentView = ViewMain
+		showChapter(st.Chapter)
+		showWiki(st.Wiki)
+		planDeadline()
+		setView(restoreView)
+		if st.Offset > 0 {
+			editorFor(restoreView).Select(st.Offset, st.Offset)
 		}
+	}
 
-		// Ensure Wiki isn't empty if loading from old file
-		if len(wikiEntries) == 0 {
-			wikiEntries = []WikiEntry{{Title: "General", Content: ""}}
+	// openProject loads filename into the editor and restores the state it
+	// was last closed in. A chapter (1-based) or view name overrides the
+	// remembered one.
+	openProject := func(filename string, chapter int, view string) error {
+		loaded, err := project.Load(filename)
+		if err != nil {
+			return err
 		}
+		rememberState()
+		// Switching projects drops unsaved edits, as it always has
+		discardJournal()
 
-		// STATE RESET
-		currentFilename = filename
-		currentChapterIndex = 0
-		currentWikiIndex = 0
-		currentView = ViewMain
-		isFocusMode = false
+		book = loaded
+		savedSum = book.Fingerprint()
+		st, _ := project.LoadState(book.Filename)
+		if chapter > 0 {
+			st.Chapter = chapter - 1
+		}
+		if view != "" {
+			st.View = view
+		}
+		showProject(st)
+		return nil
+	}
+
+	// offerRecovery asks, one journal at a time, whether to recover sessions
+	// that did not exit cleanly
+	var offerRecovery func(journals []*project.Journal)
+	offerRecovery = func(journals []*project.Journal) {
+		if len(journals) == 0 {
+			return
+		}
+		j := journals[0]
+		name := j.Filename
+		if name == "" {
+			name = "an untitled project"
+		}
+
+		modal := tview.NewModal()
+		modal.SetText(fmt.Sprintf("gowrite did not exit cleanly.\nRecover unsaved changes to %s from %s?", name, j.Updated.Format("2006-01-02 15:04:05")))
+		modal.AddButtons([]string{"Recover", "Discard", "Later"})
+		modal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.HidePage("modal")
+			switch buttonLabel {
+			case "Recover":
+				rememberState()
+				discardJournal()
+				book = j.Project
+				savedSum = ""
+				showProject(j.State)
+				// Take over the journal so the recovered text stays safe until saved
+				writeJournal()
+				if j.Path != journalPath {
+					journaler.Remove(j.Path)
+				}
+				flashStatusMessage(fmt.Sprintf(" Recovered %s - save to keep it ", name))
+				return
+			case "Discard":
+				journaler.Remove(j.Path)
+			}
+			offerRecovery(journals[1:])
+		})
 
-		setView(ViewMain)
-		loadChapter(0)
+		modal.SetBackgroundColor(tview.Styles.ContrastBackgroundColor)
+		modal.SetTextColor(tview.Styles.PrimaryTextColor)
+		modal.SetButtonBackgroundColor(tview.Styles.TitleColor)
+		modal.SetButtonTextColor(tview.Styles.PrimitiveBackgroundColor)
+		pages.AddPage("modal", modal, true, true)
+		app.SetFocus(modal)
+	}
 
-		showModal("Success", fmt.Sprintf("Loaded %s", filename))
+	loadBook := func(filename string) {
+		if err := openProject(filename, 0, ""); err != nil {
+			showModal("Error", err.Error())
+			return
+		}
+		showModal("Success", fmt.Sprintf("Loaded %s", book.Filename))
 	}
 
-	exportBook := func(filename string) {
+	exportBook := func(args []string) {
 		saveCurrentChapter()
-		if filename == "" {
-			showModal("Error", "Usage: export <filename>")
+		saveCurrentWiki()
+
+		fs := flag.NewFlagSet("export", flag.ContinueOnError)
+		opt := exportFlags(fs)
+		pos, err := parseFlags(fs, args)
+		var format export.Format
+		var filename string
+		if err == nil {
+			format, filename, err = exportTarget(pos, opt)
+		}
+		if err == errUsage {
+			showModal("Error", "Usage: export "+exportUsage+"\nFormats: "+strings.Join(export.Formats(), ", "))
+			return
+		}
+		if err != nil {
+			showModal("Error", err.Error())
 			return
 		}
-		if !strings.Contains(filename, ".") {
-			filename += ".txt"
+		if opt.Theme == "" {
+			opt.Theme = currentTheme // the site looks like the editor
 		}
 
-		var sb strings.Builder
-		for i, chap := range chapters {
-			sb.WriteString(fmt.Sprintf("# Chapter %d: %s\n\n", i+1, chap.Title))
-			sb.WriteString(chap.Content)
-			sb.WriteString("\n\n")
-		}
-		if err := os.WriteFile(filename, []byte(sb.String()), 0644); err != nil {
+		written, err := export.File(filename, format, book, *opt)
+		if err != nil {
 			showModal("Error", err.Error())
 		} else {
-			showModal("Success", fmt.Sprintf("Exported to %s", filename))
+			showModal("Success", fmt.Sprintf("Exported to %s", written))
 		}
 	}
 
-	go func() {
-		ticker := time.NewTicker(60 * time.Second)
-		for range ticker.C {
-			if currentFilename != "" {
-				app.QueueUpdateDraw(func() { saveBook(currentFilename, true) })
-			}
+	// Autosave once the project has a name
+	go every(60*time.Second, queueUpdate, func() {
+		if book.Filename != "" {
+			saveBook("", true)
 		}
-	}()
+	}, stop)
 
 	// --- FILE PICKER ---
 	showFilePicker := func() {
-		// Get list of .json files in current directory
+		// Get list of .json files and directory projects in current directory
 		files, err := os.ReadDir(".")
 		if err != nil {
 			showModal("Error", "Could not read directory")
@@ -1016,13 +2616,15 @@ func main() {
 
 		var jsonFiles []string
 		for _, file := range files {
-			if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+			if file.IsDir() && project.IsProjectDir(file.Name()) {
+				jsonFiles = append(jsonFiles, project.Filename(file.Name()))
+			} else if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
 				jsonFiles = append(jsonFiles, file.Name())
 			}
 		}
 
 		if len(jsonFiles) == 0 {
-			showModal("No Files", "No .json files found in current directory.\nUsage: open <filename>")
+			showModal("No Files", "No projects found in current directory.\nUsage: open <filename>")
 			return
 		}
 
@@ -1069,6 +2671,95 @@ func main() {
 		app.SetFocus(fileList)
 	}
 
+	// --- BACKUPS ---
+	showBackups := func() {
+		if book.Filename == "" {
+			showModal("Error", "Save the project first: 'save <name>'")
+			return
+		}
+		backups, err := project.ListBackups(book.Filename)
+		if err != nil {
+			showModal("Error", err.Error())
+			return
+		}
+		if len(backups) == 0 {
+			showModal("No Backups", fmt.Sprintf("No backups of %s yet.\nOne is taken when a save replaces an older version.", book.Filename))
+			return
+		}
+
+		backupList := tview.NewList()
+		backupList.ShowSecondaryText(false)
+		backupList.SetHighlightFullLine(true)
+		backupList.SetSelectedBackgroundColor(tview.Styles.TitleColor)
+		backupList.SetSelectedTextColor(tview.Styles.PrimitiveBackgroundColor)
+		backupList.SetBorder(true)
+		backupList.SetTitle("Backups (Enter to restore)")
+		backupList.SetBorderPadding(1, 1, 2, 2)
+
+		for _, b := range backups {
+			backup := b // Capture for closure
+			label := fmt.Sprintf("%s  (%.1f KB)", b.Time.Format("2006-01-02 15:04"), float64(b.Size)/1024)
+			backupList.AddItem(label, "", 0, func() {
+				pages.HidePage("filepicker")
+				showYesNoModal("Restore", fmt.Sprintf("Restore the backup from %s?\nThe current version is saved and backed up first.", backup.Time.Format("2006-01-02 15:04")), func() {
+					saveCurrentChapter()
+					saveCurrentWiki()
+					if err := book.Save(""); err != nil {
+						showModal("Error", err.Error())
+						return
+					}
+					if _, err := project.RestoreBackup(book.Filename, backup); err != nil {
+						showModal("Error", err.Error())
+						return
+					}
+					if err := openProject(book.Filename, book.CurrentChapter+1, viewNames[currentView]); err != nil {
+						showModal("Error", err.Error())
+						return
+					}
+					flashStatusMessage(fmt.Sprintf(" Restored backup from %s ", backup.Time.Format("2006-01-02 15:04")))
+				})
+			})
+		}
+
+		backupList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyEscape {
+				pages.HidePage("filepicker")
+				if currentView == ViewNotes {
+					app.SetFocus(notesArea)
+				} else if currentView == ViewWiki {
+					app.SetFocus(wikiArea)
+				} else {
+					app.SetFocus(textArea)
+				}
+				return nil
+			}
+			return event
+		})
+
+		pages.AddPage("filepicker", tview.NewGrid().
+			SetColumns(0, 50, 0).
+			SetRows(0, 20, 0).
+			AddItem(backupList, 1, 1, 1, 1, 0, 0, true), true, true)
+		app.SetFocus(backupList)
+	}
+
+	// setBackupPolicy handles 'backups keep N' and 'backups every N'
+	setBackupPolicy := func(setting, value string) {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			showModal("Error", "Usage: backups keep <count>  OR  backups every <minutes>")
+			return
+		}
+		policy := book.Policy()
+		if setting == "keep" {
+			policy.Keep = n
+		} else {
+			policy.Interval = n
+		}
+		book.Backups = &policy
+		flashStatusMessage(fmt.Sprintf(" Backups: keep %d, at most one every %d min (saved with the project) ", policy.Keep, policy.Interval))
+	}
+
 	// --- COMMAND PROCESSING ---
 	handleCommand := func(cmdRaw string) {
 		cmdRaw = strings.TrimSpace(cmdRaw)
@@ -1092,46 +2783,10 @@ func main() {
 			} else if currentView == ViewWiki {
 				targetArea = wikiArea
 			}
-			text := targetArea.GetText()
-			words := len(strings.Fields(text))
-			lines := strings.Count(text, "\n") + 1
-			if len(text) == 0 {
-				lines = 0
-			}
-			showModal("Stats", fmt.Sprintf("Words: %d\nChars: %d\nLines: %d", words, len(text), lines))
+			st := CountText(targetArea.GetText())
+			showModal("Stats", fmt.Sprintf("Words: %d\nChars: %d\nLines: %d", st.Words, st.Chars, st.Lines))
 		case "chapters", "list":
-			// Explicit list creation to avoid chaining errors
-			list := tview.NewList()
-			list.ShowSecondaryText(false)
-			list.SetHighlightFullLine(true)
-			list.SetSelectedBackgroundColor(tview.Styles.TitleColor)
-			list.SetSelectedTextColor(tview.Styles.PrimitiveBackgroundColor)
-			list.SetBorder(true)
-			list.SetTitle("Chapters (< & > reorder)")
-			list.SetBorderPadding(1, 1, 2, 2)
-
-			// Simple populate logic
-			for i, chap := range chapters {
-				idx := i
-				title := fmt.Sprintf("%d. %s", i+1, chap.Title)
-				if i == currentChapterIndex {
-					title += " (Current)"
-				}
-				list.AddItem(title, "", 0, func() { loadChapter(idx) })
-			}
-
-			list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-				if event.Key() == tcell.KeyEscape {
-					pages.HidePage("modal")
-					app.SetFocus(textArea)
-					return nil
-				}
-				return event
-			})
-
-			grid := tview.NewGrid().SetColumns(0, 40, 0).SetRows(0, 20, 0).AddItem(list, 1, 1, 1, 1, 0, 0, true)
-			pages.AddPage("modal", grid, true, true)
-			app.SetFocus(list)
+			showChapterManager()
 
 		case "save":
 			f := ""
@@ -1146,23 +2801,56 @@ func main() {
 				showFilePicker()
 			}
 		case "export":
-			if len(parts) > 1 {
-				exportBook(strings.Join(parts[1:], " "))
-			} else {
-				showModal("Error", "Usage: export <file>")
-			}
+			exportBook(parts[1:])
 		case "search":
-			if len(parts) > 1 {
-				term := strings.Join(parts[1:], " ")
-				targetArea := textArea
-				if currentView == ViewNotes {
-					targetArea = notesArea
-				} else if currentView == ViewWiki {
-					targetArea = wikiArea
-				}
-				count := strings.Count(targetArea.GetText(), term)
-				showModal("Search", fmt.Sprintf("Found %d of '%s'", count, term))
+			term, opt := parseSearchArgs(parts[1:])
+			searchOptions = opt
+			if term == "" {
+				openSearchBar()
+				break
+			}
+			searchTerm = term
+			if area := editorArea(); area != nil {
+				app.SetFocus(area)
+			}
+			flashStatusMessage(" " + findMatch(true, -1) + " ")
+		case "target":
+			setTarget(parts[1:])
+		case "goal":
+			if len(parts) == 1 {
+				showHistory()
+				break
+			}
+			setDailyGoal(parts[1:])
+		case "history":
+			showHistory()
+		case "sprint":
+			startSprint(parts[1:])
+		case "deadline":
+			if len(parts) == 1 {
+				showDeadline()
+				break
+			}
+			setDeadline(parts[1:])
+		case "find":
+			term, opt := parseSearchArgs(parts[1:])
+			if term == "" {
+				showModal("Find", "Usage: find [-c] [-w] [-r] <term>")
+				break
+			}
+			findInProject(term, opt)
+		case "replace":
+			rest := strings.TrimSpace(cmdRaw[len(parts[0]):])
+			if rest == "undo" {
+				undoReplace()
+				break
+			}
+			term, with, opt, all, err := parseReplaceArgs(rest)
+			if err != nil {
+				showModal("Replace", err.Error())
+				break
 			}
+			replaceText(term, with, opt, all)
 		case "spellcheck", "spell":
 			runSpellCheck()
 		case "theme":
@@ -1173,25 +2861,61 @@ func main() {
 			toggleNotes()
 		case "analyze":
 			runAnalysis()
+		case "screenplay":
+			arg := ""
+			if len(parts) > 1 {
+				arg = strings.ToLower(parts[1])
+			}
+			switch arg {
+			case "on":
+				book.Mode = project.ModeScreenplay
+				setView(currentView)
+				flashStatusMessage(" Screenplay mode: chapters are Fountain scripts, laid out alongside. Ctrl-P previews ")
+			case "off":
+				book.Mode = project.ModeProse
+				setView(currentView)
+				flashStatusMessage(" Prose mode ")
+			default:
+				state := "off"
+				if book.Mode == project.ModeScreenplay {
+					state = "on"
+				}
+				showModal("Screenplay", fmt.Sprintf("Screenplay mode is %s.\nUsage: screenplay on|off\n\nIn screenplay mode chapters are written in Fountain and laid out as a script beside the editor as you type. Ctrl-P previews the script full width, and PDF and HTML exports are set as a script.", state))
+			}
+		case "preview":
+			previewScript()
 
 		// Import plain text into chapter
 		case "import":
 			// Usage:
 			//   import <file.txt>         -> overwrite current chapter with file contents
 			//   import new <file.txt>     -> create a new chapter with file contents (title = filename)
+			//   import split <file.md>    -> split the file into chapters at its headings
+			//   import scrivener <Book.scriv> -> chapters and wiki entries from a Scrivener project
+			// Word and OpenDocument files are always split into chapters
 			if len(parts) < 2 {
-				showModal("Error", "Usage: import <file.txt>  OR  import new <file.txt>")
+				showModal("Error", "Usage: import <file.txt>  OR  import new <file.txt>  OR  import split <file.md> [--pattern regex] [--notes]  OR  import scrivener <Book.scriv>")
+				break
+			}
+
+			if parts[1] == "split" {
+				fn, opt, err := parseSplitArgs(parts[2:])
+				if err != nil {
+					showModal("Error", fmt.Sprintf("%v\nUsage: import split <file.md> [--pattern regex] [--notes]\n\nWrite spaces in a pattern as \\s, e.g. --pattern ^Chapter\\s\\d+", err))
+					break
+				}
+				importChapters(fn, opt)
 				break
 			}
 
-			// helper to validate .txt path
-			validateTxt := func(raw string) (string, bool) {
-				fn := strings.Join(strings.Fields(raw), " ")
-				fn = filepath.Clean(fn)
-				if !strings.HasSuffix(strings.ToLower(fn), ".txt") {
-					return fn, false
+			if parts[1] == "scrivener" {
+				path := filepath.Clean(strings.Join(parts[2:], " "))
+				if len(parts) < 3 || !importer.IsScrivener(path) {
+					showModal("Error", "Usage: import scrivener <Book.scriv>")
+					break
 				}
-				return fn, true
+				importChapters(path, importer.Options{})
+				break
 			}
 
 			if parts[1] == "new" {
@@ -1199,9 +2923,13 @@ func main() {
 					showModal("Error", "Usage: import new <file.txt>")
 					break
 				}
-				fn, ok := validateTxt(strings.Join(parts[2:], " "))
+				fn, ok := validateImport(strings.Join(parts[2:], " "))
 				if !ok {
-					showModal("Error", "Only .txt files supported for import.")
+					showModal("Error", "Only .txt, .md, .docx, .odt and .scriv files supported for import.")
+					break
+				}
+				if importer.IsDocument(fn) || importer.IsScrivener(fn) {
+					importChapters(fn, importer.Options{})
 					break
 				}
 
@@ -1218,10 +2946,8 @@ func main() {
 
 						title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 
-						mu.Lock()
-						chapters = append(chapters, Chapter{Title: title, Content: string(data), Notes: ""})
-						newIdx := len(chapters) - 1
-						mu.Unlock()
+						newIdx := book.AddChapter(title)
+						book.Chapters[newIdx].Content = string(data)
 
 						loadChapter(newIdx)
 						flashStatusMessage(fmt.Sprintf("Imported %s into new chapter '%s'", path, title))
@@ -1232,9 +2958,13 @@ func main() {
 			}
 
 			// default: import into current chapter (overwrite)
-			fn, ok := validateTxt(strings.Join(parts[1:], " "))
+			fn, ok := validateImport(strings.Join(parts[1:], " "))
 			if !ok {
-				showModal("Error", "Only .txt files supported for import.")
+				showModal("Error", "Only .txt, .md, .docx, .odt and .scriv files supported for import.")
+				break
+			}
+			if importer.IsDocument(fn) || importer.IsScrivener(fn) {
+				importChapters(fn, importer.Options{})
 				break
 			}
 
@@ -1248,23 +2978,20 @@ func main() {
 						return
 					}
 
-					mu.Lock()
-					defer mu.Unlock()
-
-					if currentChapterIndex < 0 || currentChapterIndex >= len(chapters) {
+					if book.CurrentChapter < 0 || book.CurrentChapter >= len(book.Chapters) {
 						// append a new chapter if none valid
 						title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
-						chapters = append(chapters, Chapter{Title: title, Content: string(data)})
-						currentChapterIndex = len(chapters) - 1
-						loadChapter(currentChapterIndex)
+						book.CurrentChapter = book.AddChapter(title)
+						book.Chapters[book.CurrentChapter].Content = string(data)
+						loadChapter(book.CurrentChapter)
 					} else {
-						chapters[currentChapterIndex].Content = string(data)
-						// update editor text/title
-						textArea.SetText(chapters[currentChapterIndex].Content, false)
-						textArea.SetTitle(fmt.Sprintf("gowrite - Chapter %d: %s", currentChapterIndex+1, chapters[currentChapterIndex].Title))
+						book.Chapters[book.CurrentChapter].Content = string(data)
+						// showChapter, not loadChapter: saving would write the old text back.
+						// Nor is the imported text counted as written this session.
+						showChapter(book.CurrentChapter)
 					}
 
-					flashStatusMessage(fmt.Sprintf("Imported %s into Chapter %d", path, currentChapterIndex+1))
+					flashStatusMessage(fmt.Sprintf("Imported %s into Chapter %d", path, book.CurrentChapter+1))
 				})
 			}(fn)
 
@@ -1278,14 +3005,14 @@ func main() {
 					if len(parts) > 2 {
 						title = strings.Join(parts[2:], " ")
 					}
-					wikiEntries = append(wikiEntries, WikiEntry{Title: title, Content: ""})
-					currentWikiIndex = len(wikiEntries) - 1
+					saveCurrentWiki()
+					showWiki(book.AddWiki(title))
 					setView(ViewWiki)
 				} else if sub == "delete" {
-					deleteWiki(currentWikiIndex)
+					deleteWiki(book.CurrentWiki)
 				} else if sub == "rename" {
 					if len(parts) > 2 {
-						renameWiki(currentWikiIndex, strings.Join(parts[2:], " "))
+						renameWiki(book.CurrentWiki, strings.Join(parts[2:], " "))
 					}
 				} else {
 					// Assume they typed 'wiki searchterm' or similar, but for now just open view
@@ -1295,6 +3022,15 @@ func main() {
 				setView(ViewWiki)
 			}
 
+		case "backups", "backup":
+			if len(parts) == 3 && (parts[1] == "keep" || parts[1] == "every") {
+				setBackupPolicy(parts[1], parts[2])
+			} else if len(parts) == 1 {
+				showBackups()
+			} else {
+				showModal("Backups", "Usage: backups  OR  backups keep <count>  OR  backups every <minutes>")
+			}
+
 		case "structure":
 			if len(parts) > 1 {
 				applyStructure(parts[1])
@@ -1302,6 +3038,36 @@ func main() {
 				showModal("Structure", "Usage: structure <name>\nOptions: 3act, hero, cat, fichtean, horror")
 			}
 
+		case "meta":
+			field, value := "", ""
+			if len(parts) > 2 {
+				field, value = strings.ToLower(parts[1]), strings.Join(parts[2:], " ")
+			}
+			switch field {
+			case "title":
+				book.Metadata.Title = value
+			case "author":
+				book.Metadata.Author = value
+			case "contact":
+				// One line per ';': legal name; address; email
+				lines := strings.Split(value, ";")
+				for i := range lines {
+					lines[i] = strings.TrimSpace(lines[i])
+				}
+				book.Metadata.Contact = strings.Join(lines, "\n")
+			default:
+				notSet := func(s string) string {
+					if s == "" {
+						return "(not set)"
+					}
+					return s
+				}
+				showModal("Metadata", fmt.Sprintf("Title: %s\nAuthor: %s\nContact: %s\n\nUsage: meta title|author <text>\nOR  meta contact <name>; <address>; <email>",
+					book.Name(), notSet(book.Metadata.Author), notSet(strings.ReplaceAll(book.Metadata.Contact, "\n", "; "))))
+				return
+			}
+			flashStatusMessage(fmt.Sprintf(" %s set to %s ", field, value))
+
 		case "chapter":
 			if len(parts) > 1 {
 				sub := strings.ToLower(parts[1])
@@ -1311,10 +3077,9 @@ func main() {
 						title = strings.Join(parts[2:], " ")
 					}
 					saveCurrentChapter()
-					chapters = append(chapters, Chapter{Title: title})
-					loadChapter(len(chapters) - 1)
+					loadChapter(book.AddChapter(title))
 				} else if sub == "delete" {
-					idx := currentChapterIndex
+					idx := book.CurrentChapter
 					if len(parts) > 2 {
 						if n, err := strconv.Atoi(parts[2]); err == nil {
 							idx = n - 1
@@ -1323,7 +3088,7 @@ func main() {
 					deleteChapter(idx)
 				} else if sub == "rename" {
 					// Supports 'chapter rename Title' (current) or 'chapter rename 1 Title'
-					idx := currentChapterIndex
+					idx := book.CurrentChapter
 					nameStart := 2
 					if len(parts) > 2 {
 						// Check if first arg is a number
@@ -1340,9 +3105,9 @@ func main() {
 		}
 	}
 
-	updateInfos := func() {
-		if currentView == ViewAnalyze {
-			position.SetText(" Read-Only ")
+	updateInfos = func() {
+		if currentView == ViewAnalyze || currentView == ViewScript {
+			position.SetText(" " + sprintStatus() + "Read-Only ")
 			return
 		}
 
@@ -1358,14 +3123,76 @@ func main() {
 		wordCount := len(strings.Fields(text))
 
 		wordCountStr := fmt.Sprintf("[%s]%d[white]", tview.Styles.SecondaryTextColor, wordCount)
-		position.SetText(fmt.Sprintf("Words: %s | Row: %d Col: %d ", wordCountStr, fromRow, fromColumn))
+		if currentView == ViewMain {
+			wordCountStr = goalStatus(wordCount)
+		}
+		position.SetText(fmt.Sprintf("%sWords: %s | Row: %d Col: %d ", sprintStatus(), wordCountStr, fromRow, fromColumn))
 	}
-	textArea.SetMovedFunc(updateInfos)
+	textArea.SetMovedFunc(func() {
+		updateInfos()
+		if currentView == ViewMain && book.Mode == project.ModeScreenplay {
+			followScript()
+		}
+	})
+	// Every change to the chapter counts towards the writing session, and
+	// shows in the script pane
+	textArea.SetChangedFunc(func() {
+		session.Count(CountText(textArea.GetText()).Words)
+		history.Record(session)
+		if currentView == ViewMain && book.Mode == project.ModeScreenplay {
+			showScript()
+		}
+	})
 	notesArea.SetMovedFunc(updateInfos)
 	wikiArea.SetMovedFunc(updateInfos)
 	updateInfos()
 
+	// The search bar matches as the term is typed, from where it opened
+	commandPalette.SetChangedFunc(func(text string) {
+		if !searchBar {
+			return
+		}
+		searchTerm = text
+		if text == "" {
+			editorArea().Select(searchFrom, searchFrom)
+			commandPalette.SetTitle(searchTitle(""))
+			return
+		}
+		commandPalette.SetTitle(searchTitle(findMatch(true, searchFrom)))
+	})
+	commandPalette.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
+		if !searchBar || e.Key() != tcell.KeyRune || e.Modifiers()&tcell.ModAlt == 0 {
+			return e
+		}
+		switch unicode.ToLower(e.Rune()) {
+		case 'c':
+			searchOptions.CaseSensitive = !searchOptions.CaseSensitive
+		case 'w':
+			searchOptions.WholeWord = !searchOptions.WholeWord
+		case 'r':
+			searchOptions.Regex = !searchOptions.Regex
+		default:
+			return e
+		}
+		status := ""
+		if searchTerm != "" {
+			status = findMatch(true, searchFrom)
+		}
+		commandPalette.SetTitle(searchTitle(status))
+		return nil
+	})
+
 	commandPalette.SetDoneFunc(func(key tcell.Key) {
+		if searchBar {
+			// Enter keeps the match selected; Esc goes back to where it was
+			area := editorArea()
+			if key == tcell.KeyEscape {
+				area.Select(searchFrom, searchFrom)
+			}
+			closeSearchBar()
+			app.SetFocus(area)
+			return
+		}
 		if key == tcell.KeyEnter {
 			cmd := commandPalette.GetText()
 			commandPalette.SetText("")
@@ -1373,7 +3200,7 @@ func main() {
 
 			// Intelligent focus restoration
 			isModal := false
-			for _, m := range []string{"help", "chapters", "list", "wordcount", "save", "open", "load", "export", "search", "replace", "spell", "theme", "analyze", "target", "chapter", "wiki", "structure", "import"} {
+			for _, m := range []string{"help", "chapters", "list", "wordcount", "save", "open", "load", "export", "search", "find", "replace", "spell", "theme", "analyze", "target", "goal", "history", "deadline", "sprint", "chapter", "wiki", "structure", "import", "backup", "meta", "screenplay", "preview"} {
 				if strings.HasPrefix(cmd, m) {
 					isModal = true
 					break
@@ -1382,7 +3209,7 @@ func main() {
 			if !isModal {
 				if currentView == ViewNotes {
 					app.SetFocus(notesArea)
-				} else if currentView == ViewAnalyze {
+				} else if currentView == ViewAnalyze || currentView == ViewScript {
 					app.SetFocus(analysisView)
 				} else if currentView == ViewWiki {
 					app.SetFocus(wikiArea)
@@ -1394,7 +3221,7 @@ func main() {
 			commandPalette.SetText("")
 			if currentView == ViewNotes {
 				app.SetFocus(notesArea)
-			} else if currentView == ViewAnalyze {
+			} else if currentView == ViewAnalyze || currentView == ViewScript {
 				app.SetFocus(analysisView)
 			} else if currentView == ViewWiki {
 				app.SetFocus(wikiArea)
@@ -1442,6 +3269,7 @@ Type to enter text.
 [yellow]Ctrl-Z[white]: Undo | [yellow]Ctrl-Y[white]: Redo
 [yellow]Ctrl-T[white]: Toggle Center View
 [yellow]Ctrl-F[white]: Toggle Focus Mode
+[yellow]F3 / Shift-F3[white]: Next / previous search match
 [blue]Enter for next page, Esc to return.`)
 
 	helpCmds := tview.NewTextView()
@@ -1452,14 +3280,28 @@ Type to enter text.
 [yellow]wiki new <name>[white]: Add entry
 [yellow]wiki rename <name>[white]: Rename entry
 [yellow]wiki delete[white]: Delete entry
-[yellow]save <file>[white]: Save project
+[yellow]save <file>[white]: Save project ([yellow]save <dir>/[white] for one Markdown file per chapter)
 [yellow]open[white]: Show file picker (or [yellow]open <file>[white] to open directly)
-[yellow]export <file>[white]: Export to text
+[yellow]export [md|epub|docx|pdf|html|fountain] <file> [3-7][white]: Export (--notes, --wiki, --front-matter, --font, --trim, --recto, --theme)
+[yellow]meta title/author/contact <text>[white]: Set the title, author and contact details for exports
+[yellow]backups[white]: List and restore backups (keep/every <N> to configure)
 [yellow]notes[white] (or Ctrl-N): Toggle Notes
+[yellow]search [-c] [-w] [-r] [term][white]: Find in the chapter, notes or wiki (case, whole word, regex); no term opens the search bar
+[yellow]find [-c] [-w] [-r] <term>[white]: List every match in the chapters, notes and wiki; Enter goes to one
+[yellow]replace [--all] <old> <new>[white]: Replace in this text or the whole project, after a preview ([yellow]replace undo[white] reverts --all)
 [yellow]analyze[white]: Hemingway Analysis Mode
+[yellow]screenplay on/off[white]: Write chapters in Fountain, laid out alongside; Ctrl-P previews
 [yellow]chapter new/delete/rename[white]: Manage chapters
+[yellow]chapters[white] (or Ctrl-G): Chapter Manager: < > move, m move to, c copy, d delete
+[yellow]target <words>[white]: Set the chapter's word goal ([yellow]target book <words>[white] for the manuscript)
+[yellow]goal daily <words>[white]: Set a daily word goal; [yellow]history[white] shows words per day, streak and pace
+[yellow]sprint <minutes> [focus][white]: Time a writing sprint, in focus mode if asked ([yellow]sprint stop[white] ends it early)
+[yellow]deadline <yyyy-mm-dd> <words>[white]: Plan words a day to reach a book target by a date; [yellow]deadline[white] shows the plan
 [yellow]import <file.txt>[white]: Import .txt into current chapter
-[yellow]import new <file.txt>[white]: Import .txt into a new chapter`)
+[yellow]import new <file.txt>[white]: Import .txt into a new chapter
+[yellow]import split <file.md> [--pattern re] [--notes][white]: Split a manuscript into chapters at its headings
+[yellow]import <file.docx|odt>[white]: Import a Word or OpenDocument file as chapters
+[yellow]import scrivener <Book.scriv>[white]: Import a Scrivener project's Draft and Research`)
 
 	// Setup the frame for Help pages
 	help := tview.NewFrame(help1)
@@ -1480,7 +3322,7 @@ Type to enter text.
 			// Restore focus
 			if currentView == ViewNotes {
 				app.SetFocus(notesArea)
-			} else if currentView == ViewAnalyze {
+			} else if currentView == ViewAnalyze || currentView == ViewScript {
 				app.SetFocus(analysisView)
 			} else if currentView == ViewWiki {
 				app.SetFocus(wikiArea)
@@ -1542,7 +3384,22 @@ Type to enter text.
 			handleCommand("chapters")
 			return nil
 		}
+		// Next and previous search match (F3, Shift-F3)
+		if e.Key() == tcell.KeyF3 || e.Key() == tcell.KeyF15 {
+			forward := e.Key() == tcell.KeyF3 && e.Modifiers()&tcell.ModShift == 0
+			if searchTerm == "" {
+				openSearchBar()
+			} else if status := findMatch(forward, -1); searchBar {
+				commandPalette.SetTitle(searchTitle(status))
+			} else {
+				flashStatusMessage(" " + status + " ")
+			}
+			return nil
+		}
 		if e.Key() == tcell.KeyCtrlE {
+			if searchBar {
+				closeSearchBar()
+			}
 			// Auto-exit Focus Mode if user wants to run a command
 			if isFocusMode {
 				toggleFocus()
@@ -1552,7 +3409,7 @@ Type to enter text.
 			} else {
 				if currentView == ViewNotes {
 					app.SetFocus(notesArea)
-				} else if currentView == ViewAnalyze {
+				} else if currentView == ViewAnalyze || currentView == ViewScript {
 					app.SetFocus(analysisView)
 				} else if currentView == ViewWiki {
 					app.SetFocus(wikiArea)
@@ -1563,7 +3420,16 @@ Type to enter text.
 			return nil
 		}
 		if e.Key() == tcell.KeyCtrlS {
-			saveBook(currentFilename, false)
+			saveBook(book.Filename, false)
+			return nil
+		}
+		// Screenplay preview (Ctrl-P)
+		if e.Key() == tcell.KeyCtrlP {
+			if currentView == ViewScript {
+				setView(ViewMain)
+			} else {
+				previewScript()
+			}
 			return nil
 		}
 		// Ctrl-N Handler
@@ -1574,7 +3440,37 @@ Type to enter text.
 		return e
 	})
 
+	// --- STARTUP PROJECT ---
+	if startArgs.Project != "" {
+		err := openProject(startArgs.Project, startArgs.Chapter, startArgs.View)
+		if errors.Is(err, os.ErrNotExist) {
+			// Start a fresh project that Ctrl-S will save under this name
+			book.Filename = project.Filename(startArgs.Project)
+			flashStatusMessage(fmt.Sprintf(" New project: %s ", book.Filename))
+		} else if err != nil {
+			showModal("Error", err.Error())
+		}
+	}
+
+	// --- CRASH RECOVERY ---
+	if savedSum == "" {
+		// A fresh project only needs journaling once it is written in
+		savedSum = book.Fingerprint()
+	}
+	recoveryDirs := []string{"."}
+	if startArgs.Project != "" {
+		recoveryDirs = append(recoveryDirs, filepath.Dir(filepath.Clean(startArgs.Project)))
+	}
+	if journals, err := project.FindJournals(recoveryDirs...); err == nil {
+		offerRecovery(journals)
+	}
+	go every(project.JournalInterval, queueUpdate, writeJournal, stop)
+
 	if err := app.SetRoot(pages, true).EnableMouse(true).EnablePaste(true).Run(); err != nil {
 		panic(err)
 	}
+	close(stop)
+	rememberState()
+	discardJournal()
+	journaler.Close()
 }
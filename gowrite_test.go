@@ -1,8 +1,16 @@
 package main
 
 import (
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"gowrite/project"
+	"gowrite/search"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
 )
 
 func TestCalculateReadability(t *testing.T) {
@@ -113,9 +121,44 @@ func TestAnalyzeTextForHemingway_MultipleIssues(t *testing.T) {
 	}
 }
 
+func TestHemingwayStats(t *testing.T) {
+	text := "He ran quickly and was kicked easily by the guards.\n\n" +
+		"This is an extremely long sentence with more than twenty words in it which should trigger the red warning for very hard readability issues that need attention."
+	got := HemingwayStats(text)
+	want := HemingwayReport{Sentences: 2, Adverbs: 3, Passive: 1, Hard: 0, VeryHard: 1}
+	if got != want {
+		t.Errorf("HemingwayStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCountText(t *testing.T) {
+	tests := []struct {
+		text string
+		want TextStats
+	}{
+		{"", TextStats{}},
+		{"one two", TextStats{Words: 2, Chars: 7, Lines: 1}},
+		{"one\ntwo three\n", TextStats{Words: 3, Chars: 14, Lines: 3}},
+	}
+	for _, tt := range tests {
+		if got := CountText(tt.text); got != tt.want {
+			t.Errorf("CountText(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFindMisspellings(t *testing.T) {
+	dict := map[string]bool{"the": true, "cat": true, "sat": true}
+	got := FindMisspellings("The cats sat on teh mat. The cat sat!", dict)
+	want := []string{"mat", "on", "teh"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("FindMisspellings() = %v, want %v", got, want)
+	}
+}
+
 func TestViewConstants(t *testing.T) {
 	// Verify constants are defined and unique
-	views := []int{ViewMain, ViewNotes, ViewAnalyze, ViewWiki}
+	views := []int{ViewMain, ViewNotes, ViewAnalyze, ViewWiki, ViewScript}
 	seen := make(map[int]bool)
 
 	for _, v := range views {
@@ -140,6 +183,50 @@ func TestViewConstants(t *testing.T) {
 	}
 }
 
+func TestRenderScreenplay(t *testing.T) {
+	got := RenderScreenplay("INT. DINER - NIGHT\n\nBrick waits.\n\nbrick\n(quietly)\nCoffee.\n\nCUT TO:")
+	// "brick" in lower case is not a cue, so the lines under it are action
+	want := "[yellow::b]INT. DINER - NIGHT[-:-:-]\n\nBrick waits.[-:-:-]\n\nbrick[-:-:-]\n(quietly)[-:-:-]\nCoffee.[-:-:-]\n\n" +
+		strings.Repeat(" ", 53) + "[yellow]CUT TO:[-:-:-]\n"
+	if got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+
+	got = RenderScreenplay("BRICK\n(quietly)\nCoffee.")
+	want = strings.Repeat(" ", 22) + "[::b]BRICK[-:-:-]\n" +
+		strings.Repeat(" ", 16) + "[gray](quietly)[-:-:-]\n" +
+		strings.Repeat(" ", 10) + "Coffee.[-:-:-]\n"
+	if got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestScriptRow(t *testing.T) {
+	text := "INT. DINER - NIGHT\n\nBRICK\n(quietly)\nCoffee.\n\nCUT TO:"
+	_, starts := layoutScreenplay(text)
+	// The heading, a blank row, the cue with its lines, a blank row
+	if want := []int{0, 2, 3, 4, 6}; !slices.Equal(starts, want) {
+		t.Fatalf("starts = %v, want %v", starts, want)
+	}
+	for _, tt := range []struct {
+		at   string
+		want int
+	}{
+		{"INT.", 0},
+		{"BRICK", 2},
+		{"quietly", 3},
+		{"Coffee", 4},
+		{"CUT", 6},
+	} {
+		if got := scriptRow(text, strings.Index(text, tt.at), starts); got != tt.want {
+			t.Errorf("scriptRow() at %q = %d, want %d", tt.at, got, tt.want)
+		}
+	}
+	if got := scriptRow(text, 0, starts); got != 0 {
+		t.Errorf("scriptRow() at the start = %d, want 0", got)
+	}
+}
+
 func TestTargetWidth(t *testing.T) {
 	if TargetWidth != 85 {
 		t.Errorf("TargetWidth should be 85, got %d", TargetWidth)
@@ -164,3 +251,270 @@ func BenchmarkAnalyzeTextForHemingway(b *testing.B) {
 		AnalyzeTextForHemingway(text)
 	}
 }
+
+func TestEvery(t *testing.T) {
+	// A stand-in for the tview event loop: every update runs on this goroutine
+	updates := make(chan func())
+	quit := make(chan struct{})
+	go func() {
+		for f := range updates {
+			f()
+		}
+		close(quit)
+	}()
+	queue := func(f func()) { updates <- f }
+
+	ticks := 0 // owned by the "UI" goroutine
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		every(time.Millisecond, queue, func() { ticks++ }, stop)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	close(stop)
+	<-done
+
+	got := make(chan int)
+	queue(func() { got <- ticks })
+	if n := <-got; n == 0 {
+		t.Error("every() never ran f")
+	}
+	close(updates)
+	<-quit
+}
+
+func TestParseSearchArgs(t *testing.T) {
+	term, opt := parseSearchArgs([]string{"-w", "--regex", "colou?r", "-c"})
+	if term != "colou?r -c" || !opt.WholeWord || !opt.Regex || opt.CaseSensitive {
+		t.Errorf("parseSearchArgs() = %q, %+v", term, opt)
+	}
+	if term, _ := parseSearchArgs(nil); term != "" {
+		t.Errorf("no arguments gave term %q", term)
+	}
+}
+
+func TestParseReplaceArgs(t *testing.T) {
+	term, with, opt, all, err := parseReplaceArgs(`-w --all "Mr Smith" "Dr  Jones"`)
+	if err != nil || term != "Mr Smith" || with != "Dr  Jones" || !opt.WholeWord || !all {
+		t.Errorf("parseReplaceArgs() = %q, %q, %+v, %v, %v", term, with, opt, all, err)
+	}
+	if term, with, _, _, err := parseReplaceArgs(`-c ""`); err != nil || term != "-c" || with != "" {
+		t.Errorf("an empty replacement: %q, %q, %v", term, with, err)
+	}
+	for _, bad := range []string{"cat", `"cat dog`, "-x cat dog"} {
+		if _, _, _, _, err := parseReplaceArgs(bad); err == nil {
+			t.Errorf("parseReplaceArgs(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestGoalProgress(t *testing.T) {
+	if got, met := goalProgress(850, 1500); got != "850/1500 56%" || met {
+		t.Errorf("goalProgress(850, 1500) = %q, %v", got, met)
+	}
+	if _, met := goalProgress(1500, 1500); !met {
+		t.Error("a reached goal is not met")
+	}
+	if got := progressBar(5, 10, 4); got != "██░░" {
+		t.Errorf("progressBar(5, 10, 4) = %q", got)
+	}
+	if got := progressBar(30, 10, 4); got != "████" {
+		t.Errorf("progressBar past the goal = %q", got)
+	}
+}
+
+func TestHistoryReport(t *testing.T) {
+	start := time.Now()
+	h := &project.History{Sessions: []project.Session{{Start: start, End: start.Add(30 * time.Minute), Chapters: []project.ChapterWords{{Title: "One", Added: 600, Deleted: 100}}}}}
+	s := &h.Sessions[0]
+	h.Sprints = []project.Sprint{{Start: start, End: start.Add(10 * time.Minute), Minutes: 25, Words: 120}}
+	report := historyReport(h, s, 1000)
+	for _, want := range []string{"500/1000 50% of the daily goal", "+500 (600 added, 100 deleted)", "Pace[white]          1000 words an hour", "+600   -100", " 25 min   +120 (stopped)"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+	if report := historyReport(&project.History{}, &project.Session{}, 0); !strings.Contains(report, "Nothing written yet") {
+		t.Errorf("empty report:\n%s", report)
+	}
+}
+
+func TestDeadlineReport(t *testing.T) {
+	deadline := time.Date(2026, 12, 31, 0, 0, 0, 0, time.Local)
+	plan := project.Plan{Deadline: deadline, DaysLeft: 10, Words: 12000, PerDay: 1000, Today: 250, Pace: 500, Projected: deadline.AddDate(0, 0, 3)}
+	report := deadlineReport(plan, 20000)
+	for _, want := range []string{"Thu 31 Dec 2026 (10 days left", "12000/20000 60%", "8000 words", "1000 words a day (today 250/1000 25%)", "Sun 3 Jan 2027, [red]3 days late"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+
+	plan.Pace = 0
+	if report := deadlineReport(plan, 20000); !strings.Contains(report, "nothing written") || strings.Contains(report, "Projected") {
+		t.Errorf("report without a pace:\n%s", report)
+	}
+	if report := deadlineReport(plan, 10000); !strings.Contains(report, "Target reached") {
+		t.Errorf("report past the target:\n%s", report)
+	}
+}
+
+func TestSprintSummary(t *testing.T) {
+	if got := sprintClock(24*time.Minute + 7400*time.Millisecond); got != "24:07" {
+		t.Errorf("sprintClock() = %q", got)
+	}
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)
+	s := project.Sprint{Start: start, End: start.Add(25 * time.Minute), Minutes: 25, Words: 500}
+	if got := sprintSummary(s); got != "25 minutes: +500 words, 1200 an hour" {
+		t.Errorf("sprintSummary() = %q", got)
+	}
+	s.End = start.Add(10 * time.Minute)
+	if got := sprintSummary(s); got != "Stopped after 10:00 of 25 minutes: +500 words, 3000 an hour" {
+		t.Errorf("sprintSummary() stopped = %q", got)
+	}
+}
+
+func TestChapterLine(t *testing.T) {
+	got := chapterLine(3, project.Chapter{Title: "The [Call]"}, 1200, false)
+	want := "   3. The [Call[]                     " + strings.Repeat(" ", 12) + "1200"
+	if got != want {
+		t.Errorf("chapterLine() = %q, want %q", got, want)
+	}
+	got = chapterLine(12, project.Chapter{Title: strings.Repeat("é", 40), Target: 1500}, 750, true)
+	if !strings.HasPrefix(got, "▸ 12. "+strings.Repeat("é", 29)+"… ") || !strings.HasSuffix(got, " 750/1500 50%") {
+		t.Errorf("chapterLine() = %q", got)
+	}
+}
+
+func TestFindHits(t *testing.T) {
+	p := project.New()
+	p.Chapters = []project.Chapter{
+		{Title: "Arrival", Content: "The lamp was lit."},
+		{Title: "Night", Content: "Dark.\nShe blew out the [old] lamp.", Notes: "lamp motif"},
+	}
+	p.Wiki[0].Content = "Props: lamp"
+	m, _ := search.Compile("lamp", search.Options{})
+	hits := search.Find(p, m)
+
+	tests := []struct {
+		label   string
+		context string
+		view    int
+	}{
+		{"Chapter 1: Arrival, line 1", "The [::u]lamp[::-] was lit.", ViewMain},
+		{"Chapter 2: Night, line 2", "She blew out the [old[] [::u]lamp[::-].", ViewMain},
+		{"Chapter 2: Night (notes), line 1", "[::u]lamp[::-] motif", ViewNotes},
+		{"Wiki: General Notes, line 1", "Props: [::u]lamp[::-]", ViewWiki},
+	}
+	if len(hits) != len(tests) {
+		t.Fatalf("Find() = %d hits, want %d", len(hits), len(tests))
+	}
+	for i, tt := range tests {
+		h := hits[i]
+		if got := hitLabel(p, h); got != tt.label {
+			t.Errorf("hitLabel(%d) = %q, want %q", i, got, tt.label)
+		}
+		if got := hitContext(h); got != tt.context {
+			t.Errorf("hitContext(%d) = %q, want %q", i, got, tt.context)
+		}
+		if got := hitView(h); got != tt.view {
+			t.Errorf("hitView(%d) = %d, want %d", i, got, tt.view)
+		}
+
+		// Going to the hit selects the match in the text it was found in
+		area := tview.NewTextArea()
+		area.SetText(h.Text(p), false)
+		area.Select(h.Match[0], h.Match[1])
+		if selected, _, _ := area.GetSelection(); selected != "lamp" {
+			t.Errorf("hit %d selects %q in %s %d", i, selected, h.Field, h.Index)
+		}
+	}
+}
+
+func TestShowInEditor_Import(t *testing.T) {
+	s := &project.Session{}
+	area := tview.NewTextArea()
+	// As the editor does: every change counts towards the session
+	area.SetChangedFunc(func() { s.Count(CountText(area.GetText()).Words) })
+
+	chapter := project.Chapter{Title: "One", Content: "a short draft"}
+	showInEditor(area, s, chapter)
+	chapter.Content = strings.Repeat("imported word ", 500)
+	showInEditor(area, s, chapter)
+	if s.Words() != 0 || len(s.Chapters) != 0 {
+		t.Fatalf("importing counted as writing: %+v", s)
+	}
+
+	area.Replace(area.GetTextLength(), area.GetTextLength(), "more")
+	if s.Words() != 1 {
+		t.Errorf("Words() after typing = %d, want 1", s.Words())
+	}
+}
+
+func TestReplaceInEditor(t *testing.T) {
+	s := &project.Session{}
+	area := tview.NewTextArea()
+	area.SetChangedFunc(func() { s.Count(CountText(area.GetText()).Words) })
+
+	chapter := project.Chapter{Title: "One", Content: "the cat sat"}
+	showInEditor(area, s, chapter)
+	chapter.Content = "the big old cat sat"
+	replaceInEditor(area, s, chapter)
+	if s.Words() != 0 || len(s.Chapters) != 0 {
+		t.Fatalf("replacing counted as writing: %+v", s)
+	}
+	if area.GetText() != chapter.Content {
+		t.Errorf("text = %q, want %q", area.GetText(), chapter.Content)
+	}
+
+	// Undoing the replace in the project is not writing either
+	chapter.Content = "the cat sat"
+	replaceInEditor(area, s, chapter)
+	if s.Words() != 0 || len(s.Chapters) != 0 {
+		t.Errorf("undoing the replace counted as writing: %+v", s)
+	}
+}
+
+func TestMarkMatches(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(20, 3)
+
+	area := tview.NewTextArea()
+	area.SetRect(0, 0, 20, 3)
+	area.SetText("café cat and cat\nCat.", false)
+	area.Draw(screen)
+	area.Select(6, 9) // the first "cat", left selected
+	area.Draw(screen)
+
+	m, err := search.Compile("cat", search.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	marked := area.GetTextStyle().Reverse(true)
+	markMatches(screen, area, m, marked)
+
+	var got []string
+	for row := range 3 {
+		var line strings.Builder
+		for col := range 20 {
+			str, style, _ := screen.Get(col, row)
+			if style == marked {
+				line.WriteString(str)
+			} else {
+				line.WriteString(".")
+			}
+		}
+		got = append(got, line.String())
+	}
+	want := []string{".............cat....", "Cat.................", "...................."}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("row %d marked %q, want %q", i, got[i], want[i])
+		}
+	}
+}
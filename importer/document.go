@@ -0,0 +1,140 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"gowrite/project"
+)
+
+// paragraph is a paragraph of a word processor document
+type paragraph struct {
+	Level int      // outline level of a heading, 0 for body text
+	Text  string   // with *italic* and **bold** marked up
+	Plain string   // without the markup
+	Notes []string // comments and footnotes anchored in it
+}
+
+// run is a stretch of a paragraph with the same emphasis
+type run struct {
+	Text         string
+	Italic, Bold bool
+}
+
+// paragraphText joins runs into a paragraph's text, with Markdown around
+// the emphasis. Bold italic is marked as italic: the exports read ***text***
+// as bold with stray asterisks, and italics matter more in a manuscript.
+func paragraphText(runs []run) paragraph {
+	// Merge neighbouring runs with the same emphasis, so a word split
+	// across runs by a spell checker is marked once
+	var merged []run
+	for _, r := range runs {
+		if n := len(merged); n > 0 && merged[n-1].Italic == r.Italic && merged[n-1].Bold == r.Bold {
+			merged[n-1].Text += r.Text
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	var text, plain strings.Builder
+	for _, r := range merged {
+		plain.WriteString(r.Text)
+		mark := ""
+		switch {
+		case r.Italic:
+			mark = "*"
+		case r.Bold:
+			mark = "**"
+		}
+		// Markers hug the words: "* word*" is not emphasis
+		core := strings.TrimSpace(r.Text)
+		if mark == "" || core == "" {
+			text.WriteString(r.Text)
+			continue
+		}
+		start := strings.Index(r.Text, core)
+		text.WriteString(r.Text[:start] + mark + core + mark + r.Text[start+len(core):])
+	}
+	return paragraph{Text: strings.TrimSpace(text.String()), Plain: strings.TrimSpace(plain.String())}
+}
+
+// documentChapters groups paragraphs into chapters at the headings of the
+// shallowest level used more than once, or at paragraphs matching the
+// pattern. Other headings stay in the text as Markdown headings.
+func documentChapters(paras []paragraph, opt Options) []project.Chapter {
+	var levels []int
+	for _, p := range paras {
+		if p.Level > 0 {
+			levels = append(levels, p.Level)
+		}
+	}
+	level := splitLevel(levels)
+
+	chapters := []project.Chapter{{Title: FrontMatter}}
+	var body, notes []string
+	flush := func() {
+		c := &chapters[len(chapters)-1]
+		c.Content = strings.Join(body, "\n\n")
+		c.Notes = strings.Join(notes, "\n\n")
+		body, notes = nil, nil
+	}
+	for _, p := range paras {
+		title, ok := "", false
+		if opt.Pattern != nil {
+			title, ok = opt.title(p.Plain)
+		} else if p.Level > 0 && p.Level == level {
+			title, ok = p.Plain, true
+		}
+		if ok {
+			flush()
+			chapters = append(chapters, project.Chapter{Title: title})
+			notes = append(notes, p.Notes...)
+			continue
+		}
+
+		notes = append(notes, p.Notes...)
+		switch {
+		case p.Text == "":
+		case p.Level > 0:
+			body = append(body, strings.Repeat("#", min(p.Level, 6))+" "+p.Plain)
+		default:
+			body = append(body, p.Text)
+		}
+	}
+	flush()
+
+	if front := chapters[0]; front.Content == "" && front.Notes == "" {
+		chapters = chapters[1:]
+	}
+	return chapters
+}
+
+// unzip reads the named parts of a zipped document. Missing parts are left
+// out of the map, so optional ones such as comments can be looked for.
+func unzip(data []byte, names ...string) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a valid document: %v", err)
+	}
+	parts := make(map[string][]byte)
+	for _, f := range zr.File {
+		for _, name := range names {
+			if f.Name != name {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			parts[name], err = io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return parts, nil
+}
@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gowrite/project"
+)
+
+// zipped builds a document from its parts
+func zipped(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const wordNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+
+func testDOCX(t *testing.T) []byte {
+	return zipped(t, map[string]string{
+		"word/document.xml": `<?xml version="1.0"?><w:document ` + wordNS + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:t>The Call</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="berschrift1"/></w:pPr><w:r><w:t>One</w:t></w:r></w:p>
+<w:p><w:pPr><w:tabs><w:tab w:val="left" w:pos="720"/></w:tabs></w:pPr>
+  <w:r><w:t xml:space="preserve">It was </w:t></w:r>
+  <w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">very </w:t></w:r>
+  <w:r><w:rPr><w:i/></w:rPr><w:t>dark</w:t></w:r>
+  <w:r><w:t xml:space="preserve"> and </w:t></w:r>
+  <w:r><w:rPr><w:b/></w:rPr><w:t>loud</w:t></w:r>
+  <w:r><w:rPr><w:i w:val="0"/></w:rPr><w:t>.</w:t></w:r>
+  <w:commentRangeStart w:id="0"/><w:r><w:commentReference w:id="0"/></w:r>
+</w:p>
+<w:p><w:r><w:t>Kept</w:t></w:r><w:del><w:r><w:delText>gone</w:delText></w:r></w:del><w:r><w:t xml:space="preserve"> text</w:t></w:r><w:r><w:rPr><w:rStyle w:val="Emphasis"/></w:rPr><w:t>!</w:t></w:r></w:p>
+<w:p/>
+<w:p><w:pPr><w:outlineLvl w:val="0"/></w:pPr><w:r><w:t>Two</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Morning</w:t></w:r></w:p>
+<w:p><w:r><w:t>Dawn.</w:t></w:r><w:r><w:footnoteReference w:id="2"/></w:r></w:p>
+</w:body></w:document>`,
+		"word/styles.xml": `<?xml version="1.0"?><w:styles ` + wordNS + `>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/></w:style>
+<w:style w:type="paragraph" w:styleId="berschrift1"><w:name w:val="heading 1"/></w:style>
+<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/></w:style>
+<w:style w:type="character" w:styleId="Emphasis"><w:name w:val="Emphasis"/><w:rPr><w:i/></w:rPr></w:style>
+</w:styles>`,
+		"word/comments.xml": `<?xml version="1.0"?><w:comments ` + wordNS + `>
+<w:comment w:id="0" w:author="Ed"><w:p><w:r><w:t>Too loud?</w:t></w:r></w:p><w:p><w:r><w:t>Check.</w:t></w:r></w:p></w:comment>
+</w:comments>`,
+		"word/footnotes.xml": `<?xml version="1.0"?><w:footnotes ` + wordNS + `>
+<w:footnote w:id="2"><w:p><w:r><w:t>Sunrise was at six.</w:t></w:r></w:p></w:footnote>
+</w:footnotes>`,
+	})
+}
+
+func TestDOCX(t *testing.T) {
+	got, err := DOCX(testDOCX(t), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []project.Chapter{
+		{Title: FrontMatter, Content: "The Call"},
+		{Title: "One", Content: "It was *very dark* and **loud**.\n\nKept text*!*", Notes: "Too loud?\nCheck."},
+		{Title: "Two", Content: "## Morning\n\nDawn.", Notes: "Sunrise was at six."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DOCX:\n got %q\nwant %q", got, want)
+	}
+
+	if _, err := DOCX([]byte("not a zip"), Options{}); err == nil {
+		t.Error("DOCX of a text file did not fail")
+	}
+}
+
+func TestDOCX_Pattern(t *testing.T) {
+	got, err := DOCX(testDOCX(t), Options{Pattern: regexp.MustCompile(`^Kept`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].Title != "Kept text!" || got[1].Content != "# Two\n\n## Morning\n\nDawn." {
+		t.Errorf("DOCX = %q", got)
+	}
+}
+
+func TestODT(t *testing.T) {
+	data := zipped(t, map[string]string{
+		"content.xml": `<?xml version="1.0"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<office:automatic-styles>
+  <style:style style:name="T1" style:family="text"><style:text-properties fo:font-style="italic"/></style:style>
+  <style:style style:name="T2" style:family="text" style:parent-style-name="Strong"/>
+</office:automatic-styles>
+<office:body><office:text>
+  <text:tracked-changes><text:changed-region><text:deletion><text:p>gone</text:p></text:deletion></text:changed-region></text:tracked-changes>
+  <text:h text:outline-level="1">One</text:h>
+  <text:p>It  was <text:span text:style-name="T1">very dark</text:span>
+   and<text:s text:c="2"/><text:span text:style-name="T2">loud</text:span>.<office:annotation><dc:creator>Ed</dc:creator><dc:date>2026-01-01</dc:date><text:p>Too loud?</text:p>
+   <text:p>Check.</text:p></office:annotation></text:p>
+  <text:p/>
+  <text:h text:outline-level="1">Two</text:h>
+  <text:list><text:list-item><text:p>Dawn.<text:note text:note-class="footnote"><text:note-citation>1</text:note-citation><text:note-body><text:p>Sunrise was at six.</text:p></text:note-body></text:note></text:p></text:list-item></text:list>
+</office:text></office:body></office:document-content>`,
+		"styles.xml": `<?xml version="1.0"?>
+<office:document-styles xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0">
+<office:styles><style:style style:name="Strong" style:family="text"><style:text-properties fo:font-weight="bold"/></style:style></office:styles>
+</office:document-styles>`,
+	})
+
+	got, err := ODT(data, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []project.Chapter{
+		{Title: "One", Content: "It was *very dark* and  **loud**.", Notes: "Too loud?\nCheck."},
+		{Title: "Two", Content: "Dawn.", Notes: "Sunrise was at six."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ODT:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "Draft Two.md")
+	os.WriteFile(name, []byte("No headings here."), 0644)
+
+	got, err := File(name, Options{})
+	if err != nil || len(got) != 1 || got[0].Title != "Draft Two" {
+		t.Errorf("File = %q, %v", got, err)
+	}
+
+	bad := filepath.Join(dir, "broken.docx")
+	os.WriteFile(bad, []byte("not a zip"), 0644)
+	if _, err := File(bad, Options{}); err == nil {
+		t.Error("File of a broken document did not fail")
+	}
+}
@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"gowrite/project"
+)
+
+// docxStyle is what an import needs of a Word style: the heading level of a
+// paragraph style and the emphasis of either kind
+type docxStyle struct {
+	ID   string `xml:"styleId,attr"`
+	Name struct {
+		Val string `xml:"val,attr"`
+	} `xml:"name"`
+	BasedOn struct {
+		Val string `xml:"val,attr"`
+	} `xml:"basedOn"`
+	Outline *struct {
+		Val int `xml:"val,attr"`
+	} `xml:"pPr>outlineLvl"`
+	Italic *docxToggle `xml:"rPr>i"`
+	Bold   *docxToggle `xml:"rPr>b"`
+}
+
+// docxToggle is an on/off property such as <w:i/> or <w:i w:val="0"/>
+type docxToggle struct {
+	Val string `xml:"val,attr"`
+}
+
+func (t *docxToggle) on() bool {
+	if t == nil {
+		return false
+	}
+	switch t.Val {
+	case "0", "false", "off", "none":
+		return false
+	}
+	return true
+}
+
+// docxStyles indexes a document's styles by ID
+type docxStyles map[string]docxStyle
+
+// level is the heading level of a paragraph style: "heading 2" is 2, as is
+// any style outlined at level 1 (they count from 0). Body text is 0.
+func (s docxStyles) level(id string) int {
+	for depth := 0; depth < 10 && id != ""; depth++ {
+		style, ok := s[id]
+		if !ok {
+			break
+		}
+		name := strings.ToLower(style.Name.Val)
+		if n, err := strconv.Atoi(strings.TrimPrefix(name, "heading ")); err == nil && strings.HasPrefix(name, "heading ") {
+			return n
+		}
+		if style.Outline != nil && style.Outline.Val < 9 {
+			return style.Outline.Val + 1
+		}
+		id = style.BasedOn.Val
+	}
+	return 0
+}
+
+// DOCX reads a Word document into chapters. Paragraphs are split at the
+// heading styles (or the pattern), italics and bold are marked up and
+// comments and footnotes become the chapter's notes.
+func DOCX(data []byte, opt Options) ([]project.Chapter, error) {
+	parts, err := unzip(data, "word/document.xml", "word/styles.xml", "word/comments.xml", "word/footnotes.xml")
+	if err != nil {
+		return nil, err
+	}
+	if parts["word/document.xml"] == nil {
+		return nil, errors.New("not a Word document")
+	}
+
+	styles := make(docxStyles)
+	if data := parts["word/styles.xml"]; data != nil {
+		var doc struct {
+			Styles []docxStyle `xml:"style"`
+		}
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		for _, s := range doc.Styles {
+			styles[s.ID] = s
+		}
+	}
+
+	// Comments and footnotes are read first, to be attached where they
+	// are referenced
+	notes := make(map[string]string)
+	for _, part := range []string{"word/comments.xml", "word/footnotes.xml"} {
+		if parts[part] == nil {
+			continue
+		}
+		err := docxParagraphs(parts[part], styles, nil, func(container string, p paragraph) {
+			if p.Text != "" {
+				notes[part+container] = strings.TrimSpace(notes[part+container] + "\n" + p.Text)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var paras []paragraph
+	refs := map[string]string{"commentReference": "word/comments.xml", "footnoteReference": "word/footnotes.xml"}
+	lookup := func(kind, id string) string { return notes[refs[kind]+id] }
+	err = docxParagraphs(parts["word/document.xml"], styles, lookup, func(_ string, p paragraph) {
+		paras = append(paras, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return documentChapters(paras, opt), nil
+}
+
+// docxParagraphs walks the paragraphs of a document part, passing each to
+// add with the ID of the comment or footnote holding it. note looks up the
+// text of a comment or footnote reference.
+func docxParagraphs(data []byte, styles docxStyles, note func(kind, id string) string, add func(container string, p paragraph)) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	attr := func(e xml.StartElement, name string) string {
+		for _, a := range e.Attr {
+			if a.Name.Local == name {
+				return a.Value
+			}
+		}
+		return ""
+	}
+
+	var (
+		container    string
+		inPara       bool
+		level        int
+		runs         []run
+		notes        []string
+		italic, bold bool // of the current run
+		baseI, baseB bool // of the paragraph style
+		inRunProps   bool
+	)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "comment", "footnote":
+				container = attr(t, "id")
+			case "p":
+				inPara, level, runs, notes = true, 0, nil, nil
+				baseI, baseB = false, false
+			case "pStyle":
+				style := styles[attr(t, "val")]
+				level = styles.level(attr(t, "val"))
+				baseI, baseB = style.Italic.on(), style.Bold.on()
+			case "outlineLvl":
+				if n, err := strconv.Atoi(attr(t, "val")); err == nil && n < 9 {
+					level = n + 1
+				}
+			case "r":
+				italic, bold = baseI, baseB
+			case "rPr":
+				inRunProps = true
+			case "rStyle":
+				style := styles[attr(t, "val")]
+				italic, bold = italic || style.Italic.on(), bold || style.Bold.on()
+			case "i", "b":
+				if inRunProps {
+					on := (&docxToggle{Val: attr(t, "val")}).on()
+					if t.Name.Local == "i" {
+						italic = on
+					} else {
+						bold = on
+					}
+				}
+			case "t":
+				var text string
+				if err := d.DecodeElement(&text, &t); err != nil {
+					return err
+				}
+				runs = append(runs, run{Text: text, Italic: italic, Bold: bold})
+			case "tab":
+				runs = append(runs, run{Text: "\t", Italic: italic, Bold: bold})
+			case "br", "cr":
+				if attr(t, "type") != "page" && attr(t, "type") != "column" {
+					runs = append(runs, run{Text: "\n", Italic: italic, Bold: bold})
+				}
+			case "commentReference", "footnoteReference":
+				if note != nil {
+					if text := note(t.Name.Local, attr(t, "id")); text != "" {
+						notes = append(notes, text)
+					}
+				}
+			case "tabs", "del", "moveFrom", "Fallback":
+				// Tab stops, deleted and moved-away text, and the copy of a
+				// drawing kept for older versions of Word
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "rPr":
+				inRunProps = false
+			case "p":
+				if inPara {
+					p := paragraphText(runs)
+					p.Level, p.Notes = level, notes
+					add(container, p)
+				}
+				inPara = false
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package importer reads manuscripts written elsewhere into gowrite
+// chapters: plain text and Markdown, Word (.docx), OpenDocument (.odt) and
+// Scrivener projects.
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gowrite/project"
+)
+
+// Options control how a manuscript is split into chapters
+type Options struct {
+	// Pattern matches the lines that start a chapter. Its first group, if
+	// it has one and it matched text, is the title; otherwise the whole
+	// line is. Nil splits on headings.
+	Pattern *regexp.Regexp
+	// Notes moves <!-- comments --> and "::: notes" sections of a text file
+	// into each chapter's Notes instead of leaving them in the text.
+	// Comments in documents always go to Notes.
+	Notes bool
+}
+
+// FrontMatter titles text found before the first chapter heading
+const FrontMatter = "Front Matter"
+
+// Extensions are the file types File reads
+var Extensions = []string{".txt", ".md", ".markdown", ".docx", ".odt"}
+
+// ParsePattern compiles a --pattern, which is matched against each line
+func ParsePattern(s string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", s, err)
+	}
+	return re, nil
+}
+
+// title reports whether line matches the pattern and the chapter title it
+// gives
+func (opt Options) title(line string) (string, bool) {
+	m := opt.Pattern.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	if len(m) > 1 && strings.TrimSpace(m[1]) != "" {
+		return strings.TrimSpace(m[1]), true
+	}
+	return strings.TrimSpace(line), true
+}
+
+// IsDocument reports whether filename is a word processor document, which
+// is always imported split into chapters
+func IsDocument(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".docx", ".odt":
+		return true
+	}
+	return false
+}
+
+// File reads a manuscript and splits it into chapters. Text is split as
+// Split does; documents at their heading styles. A file with no headings
+// becomes one chapter named after it.
+func File(filename string, opt Options) ([]project.Chapter, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var chapters []project.Chapter
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".docx":
+		chapters, err = DOCX(data, opt)
+	case ".odt":
+		chapters, err = ODT(data, opt)
+	default:
+		chapters = Split(string(data), opt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if len(chapters) == 1 && chapters[0].Title == FrontMatter {
+		chapters[0].Title = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+	return chapters, nil
+}
+
+// Read imports a Scrivener project, or any file File reads. Only Scrivener
+// projects give wiki entries.
+func Read(path string, opt Options) ([]project.Chapter, []project.WikiEntry, error) {
+	if IsScrivener(path) {
+		return Scrivener(path)
+	}
+	chapters, err := File(path, opt)
+	return chapters, nil, err
+}
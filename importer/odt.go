@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gowrite/project"
+)
+
+// odtStyle is the emphasis of an OpenDocument style and the style it
+// inherits from
+type odtStyle struct {
+	Name   string `xml:"name,attr"`
+	Parent string `xml:"parent-style-name,attr"`
+	Text   *struct {
+		FontStyle  string `xml:"font-style,attr"`
+		FontWeight string `xml:"font-weight,attr"`
+	} `xml:"text-properties"`
+}
+
+// odtStyles indexes the styles of a document by name
+type odtStyles map[string]odtStyle
+
+// emphasis reports whether a style, or one it inherits from, is italic and
+// bold
+func (s odtStyles) emphasis(name string) (italic, bold bool) {
+	var gotI, gotB bool
+	for depth := 0; depth < 10 && name != ""; depth++ {
+		style, ok := s[name]
+		if !ok {
+			break
+		}
+		if t := style.Text; t != nil {
+			if !gotI && t.FontStyle != "" {
+				italic, gotI = t.FontStyle == "italic" || t.FontStyle == "oblique", true
+			}
+			if !gotB && t.FontWeight != "" {
+				n, err := strconv.Atoi(t.FontWeight)
+				bold, gotB = t.FontWeight == "bold" || (err == nil && n >= 600), true
+			}
+		}
+		name = style.Parent
+	}
+	return italic, bold
+}
+
+// odtNote collects the paragraphs of an annotation or footnote, which sits
+// inside the paragraph at depth
+type odtNote struct {
+	depth int
+	paras []string
+}
+
+// odtSpaceRegex collapses white space, which OpenDocument text does not
+// keep except as <text:s/>
+var odtSpaceRegex = regexp.MustCompile(`[ \t\r\n]+`)
+
+// ODT reads an OpenDocument text into chapters. Paragraphs are split at
+// the headings (or the pattern), italics and bold are marked up and
+// comments and footnotes become the chapter's notes.
+func ODT(data []byte, opt Options) ([]project.Chapter, error) {
+	parts, err := unzip(data, "content.xml", "styles.xml")
+	if err != nil {
+		return nil, err
+	}
+	if parts["content.xml"] == nil {
+		return nil, errors.New("not an OpenDocument text")
+	}
+
+	// Named styles live in styles.xml, the automatic styles of direct
+	// formatting in content.xml
+	styles := make(odtStyles)
+	for _, part := range []string{"styles.xml", "content.xml"} {
+		if parts[part] == nil {
+			continue
+		}
+		var doc struct {
+			Styles    []odtStyle `xml:"styles>style"`
+			Automatic []odtStyle `xml:"automatic-styles>style"`
+		}
+		if err := xml.Unmarshal(parts[part], &doc); err != nil {
+			return nil, err
+		}
+		for _, s := range append(doc.Styles, doc.Automatic...) {
+			styles[s.Name] = s
+		}
+	}
+
+	paras, err := odtParagraphs(parts["content.xml"], styles)
+	if err != nil {
+		return nil, err
+	}
+	return documentChapters(paras, opt), nil
+}
+
+// odtParagraphs walks the paragraphs and headings of a document's body.
+// The paragraphs of an annotation or footnote become a note on the
+// paragraph holding it.
+func odtParagraphs(data []byte, styles odtStyles) ([]paragraph, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	attr := func(e xml.StartElement, name string) string {
+		for _, a := range e.Attr {
+			if a.Name.Local == name {
+				return a.Value
+			}
+		}
+		return ""
+	}
+
+	// open is a paragraph being read; a note inside one starts another
+	type open struct {
+		paragraph
+		runs     []run
+		emphasis [][2]bool // of the enclosing spans
+	}
+	var (
+		paras []paragraph
+		stack []*open
+		inner []odtNote // the notes being read
+	)
+	top := func() *open {
+		if len(stack) == 0 {
+			return nil
+		}
+		return stack[len(stack)-1]
+	}
+	add := func(text string) {
+		// Text of a note outside its paragraphs, such as white space
+		// between them, belongs to neither
+		if n := len(inner); n > 0 && len(stack) <= inner[n-1].depth {
+			return
+		}
+		if p := top(); p != nil {
+			e := p.emphasis[len(p.emphasis)-1]
+			p.runs = append(p.runs, run{Text: text, Italic: e[0], Bold: e[1]})
+		}
+	}
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return paras, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p", "h":
+				italic, bold := styles.emphasis(attr(t, "style-name"))
+				p := &open{emphasis: [][2]bool{{italic, bold}}}
+				if t.Name.Local == "h" {
+					p.Level = 1
+					if n, err := strconv.Atoi(attr(t, "outline-level")); err == nil && n > 0 {
+						p.Level = n
+					}
+				}
+				stack = append(stack, p)
+			case "span":
+				if p := top(); p != nil {
+					e := p.emphasis[len(p.emphasis)-1]
+					italic, bold := styles.emphasis(attr(t, "style-name"))
+					p.emphasis = append(p.emphasis, [2]bool{e[0] || italic, e[1] || bold})
+				}
+			case "s":
+				n, err := strconv.Atoi(attr(t, "c"))
+				if err != nil || n < 1 {
+					n = 1
+				}
+				add(strings.Repeat(" ", n))
+			case "tab":
+				add("\t")
+			case "line-break":
+				add("\n")
+			case "annotation", "note-body":
+				inner = append(inner, odtNote{depth: len(stack)})
+			case "note-citation", "tracked-changes", "creator", "date":
+				// Footnote numbers, deleted text kept for change tracking
+				// and the author and date of a comment
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.CharData:
+			add(odtSpaceRegex.ReplaceAllString(string(t), " "))
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "span":
+				if p := top(); p != nil && len(p.emphasis) > 1 {
+					p.emphasis = p.emphasis[:len(p.emphasis)-1]
+				}
+			case "p", "h":
+				p := top()
+				if p == nil {
+					break
+				}
+				stack = stack[:len(stack)-1]
+				done := paragraphText(p.runs)
+				done.Level, done.Notes = p.Level, p.Notes
+				if n := len(inner); n > 0 && len(stack) > 0 {
+					// A paragraph of a note
+					if done.Text != "" {
+						inner[n-1].paras = append(inner[n-1].paras, done.Text)
+					}
+					break
+				}
+				paras = append(paras, done)
+			case "annotation", "note-body":
+				n := len(inner)
+				if n == 0 {
+					break
+				}
+				note := strings.Join(inner[n-1].paras, "\n")
+				inner = inner[:n-1]
+				if p := top(); p != nil && note != "" {
+					p.Notes = append(p.Notes, note)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package importer
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// rtfSkip are destinations whose text is not part of the document: tables
+// of fonts and styles, document info, pictures, field instructions and the
+// like
+var rtfSkip = map[string]bool{
+	"fonttbl": true, "colortbl": true, "expandedcolortbl": true, "stylesheet": true,
+	"listtable": true, "listoverridetable": true, "info": true, "pict": true,
+	"object": true, "header": true, "headerl": true, "headerr": true, "headerf": true,
+	"footer": true, "footerl": true, "footerr": true, "footerf": true,
+	"fldinst": true, "footnote": true, "annotation": true, "rsidtbl": true,
+	"generator": true, "xmlnstbl": true, "themedata": true, "latentstyles": true,
+	"datastore": true, "colorschememapping": true, "pgdsctbl": true, "revtbl": true,
+}
+
+// rtfSymbols are control words that stand for a character
+var rtfSymbols = map[string]string{
+	"emdash": "—", "endash": "–", "lquote": "‘", "rquote": "’",
+	"ldblquote": "“", "rdblquote": "”", "bullet": "•", "tab": "\t", "line": "\n",
+}
+
+// cp1252 maps the bytes 0x80 to 0x9F of Windows-1252, where it differs
+// from Latin-1; RTF files written on Macs and Windows both use it
+var cp1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// rtfGroup is the state a {group} inherits and restores
+type rtfGroup struct {
+	italic, bold bool
+	skip         bool
+	uc           int // characters to skip after a \u escape
+}
+
+// rtfParagraphs reads the paragraphs of an RTF document, with their
+// italics and bold
+func rtfParagraphs(data []byte) []paragraph {
+	var (
+		paras   []paragraph
+		runs    []run
+		stack   []rtfGroup
+		g       = rtfGroup{uc: 1}
+		pending int  // characters still to skip after a \u escape
+		high    rune // the first half of a surrogate pair
+	)
+	text := func(s string) {
+		if g.skip || s == "" {
+			return
+		}
+		if n := len(runs); n > 0 && runs[n-1].Italic == g.italic && runs[n-1].Bold == g.bold {
+			runs[n-1].Text += s
+			return
+		}
+		runs = append(runs, run{Text: s, Italic: g.italic, Bold: g.bold})
+	}
+	par := func() {
+		if !g.skip {
+			paras = append(paras, paragraphText(runs))
+			runs = nil
+		}
+	}
+	char := func(s string) {
+		if pending > 0 {
+			pending--
+			return
+		}
+		text(s)
+	}
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '{':
+			stack = append(stack, g)
+			pending = 0
+		case '}':
+			if n := len(stack); n > 0 {
+				g, stack = stack[n-1], stack[:n-1]
+			}
+			pending = 0
+		case '\r', '\n':
+		case '\\':
+			if i+1 >= len(data) {
+				break
+			}
+			i++
+			c = data[i]
+			switch {
+			case c == '\'' && i+2 < len(data):
+				b, err := strconv.ParseUint(string(data[i+1:i+3]), 16, 8)
+				i += 2
+				if err == nil {
+					char(string(decode1252(byte(b))))
+				}
+			case c == '*':
+				g.skip = true
+			case c == '\\' || c == '{' || c == '}':
+				char(string(c))
+			case c == '~':
+				char(" ")
+			case c == '_':
+				char("-")
+			case c == '\r' || c == '\n':
+				par()
+			case isLetter(c):
+				// A control word, an optional number and a space ending it
+				start := i
+				for i < len(data) && isLetter(data[i]) {
+					i++
+				}
+				word := string(data[start:i])
+				numStart := i
+				if i < len(data) && data[i] == '-' {
+					i++
+				}
+				for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+					i++
+				}
+				param, hasParam := 0, i > numStart
+				if hasParam {
+					param, _ = strconv.Atoi(string(data[numStart:i]))
+				}
+				if i >= len(data) || data[i] != ' ' {
+					i-- // the delimiter is part of the text
+				}
+
+				switch {
+				case rtfSkip[word]:
+					g.skip = true
+				case word == "par" || word == "sect":
+					par()
+				case word == "i":
+					g.italic = !hasParam || param != 0
+				case word == "b":
+					g.bold = !hasParam || param != 0
+				case word == "plain":
+					g.italic, g.bold = false, false
+				case word == "uc":
+					g.uc = param
+				case word == "u":
+					if param < 0 {
+						param += 65536
+					}
+					r := rune(param)
+					pending = 0
+					switch {
+					case utf16.IsSurrogate(r) && high == 0:
+						high = r
+					case utf16.IsSurrogate(r):
+						char(string(utf16.DecodeRune(high, r)))
+						high = 0
+					default:
+						char(string(r))
+					}
+					pending = g.uc
+				case rtfSymbols[word] != "":
+					char(rtfSymbols[word])
+				}
+			}
+		default:
+			char(string(decode1252(c)))
+		}
+	}
+	if len(runs) > 0 {
+		paras = append(paras, paragraphText(runs))
+	}
+	return paras
+}
+
+// rtfText reads an RTF document as text: a paragraph to a line, with a
+// blank line between paragraphs and Markdown around emphasis
+func rtfText(data []byte) string {
+	var lines []string
+	for _, p := range rtfParagraphs(data) {
+		if p.Text != "" {
+			lines = append(lines, p.Text)
+		}
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// decode1252 is the character a Windows-1252 byte stands for
+func decode1252(b byte) rune {
+	if b >= 0x80 && b < 0xA0 {
+		return cp1252[b-0x80]
+	}
+	return rune(b)
+}
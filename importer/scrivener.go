@@ -0,0 +1,187 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gowrite/project"
+)
+
+// scrivItem is a document or folder of a Scrivener binder
+type scrivItem struct {
+	UUID     string      `xml:"UUID,attr"` // Scrivener 3
+	ID       string      `xml:"ID,attr"`   // Scrivener 2 and Windows
+	Type     string      `xml:"Type,attr"`
+	Title    string      `xml:"Title"`
+	Children []scrivItem `xml:"Children>BinderItem"`
+}
+
+// scrivWiki are the top-level binder folders that become Story Wiki
+// entries, besides Research
+var scrivWiki = map[string]bool{"characters": true, "places": true, "locations": true}
+
+// SceneBreak separates the scenes of a Scrivener chapter
+const SceneBreak = "* * *"
+
+// IsScrivener reports whether path is a Scrivener project: the .scriv
+// folder or the .scrivx binder inside it
+func IsScrivener(path string) bool {
+	switch strings.ToLower(filepath.Ext(strings.TrimRight(path, `/\`))) {
+	case ".scriv", ".scrivx":
+		return true
+	}
+	return false
+}
+
+// scrivProject reads the files of a Scrivener project
+type scrivProject struct {
+	dir string
+}
+
+// file reads one of an item's files: "content.rtf", "notes.rtf" or
+// "synopsis.txt". Scrivener 3 keeps them in a folder per item; older
+// versions name them after the item's number. A missing file is empty.
+func (s scrivProject) file(item scrivItem, name string) []byte {
+	var path string
+	if item.UUID != "" {
+		path = filepath.Join(s.dir, "Files", "Data", item.UUID, name)
+	} else {
+		base, ext := strings.TrimSuffix(name, filepath.Ext(name)), filepath.Ext(name)
+		if base == "content" {
+			path = filepath.Join(s.dir, "Files", "Docs", item.ID+ext)
+		} else {
+			path = filepath.Join(s.dir, "Files", "Docs", item.ID+"_"+base+ext)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	return data
+}
+
+func (s scrivProject) text(item scrivItem) string {
+	return rtfText(s.file(item, "content.rtf"))
+}
+
+// notes are an item's synopsis and its notes, a paragraph apart
+func (s scrivProject) notes(item scrivItem) string {
+	var parts []string
+	if synopsis := strings.TrimSpace(string(s.file(item, "synopsis.txt"))); synopsis != "" {
+		parts = append(parts, synopsis)
+	}
+	if notes := rtfText(s.file(item, "notes.rtf")); notes != "" {
+		parts = append(parts, notes)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Scrivener reads a Scrivener project. Each document or folder at the top
+// of the Draft (Manuscript) becomes a chapter; a folder's documents are its
+// scenes. Folders holding folders are parts, whose folders are the
+// chapters. Synopses and notes go into the chapter's Notes, and the
+// documents under Research, Characters and Places become wiki entries.
+func Scrivener(path string) ([]project.Chapter, []project.WikiEntry, error) {
+	binder := path
+	if !strings.EqualFold(filepath.Ext(path), ".scrivx") {
+		found, _ := filepath.Glob(filepath.Join(path, "*.scrivx"))
+		if len(found) == 0 {
+			return nil, nil, fmt.Errorf("%s: no .scrivx binder found", path)
+		}
+		binder = found[0]
+	}
+	data, err := os.ReadFile(binder)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc struct {
+		Items []scrivItem `xml:"Binder>BinderItem"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", binder, err)
+	}
+
+	s := scrivProject{dir: filepath.Dir(binder)}
+	var chapters []project.Chapter
+	var wiki []project.WikiEntry
+	draft := false
+	for _, item := range doc.Items {
+		switch {
+		case item.Type == "DraftFolder":
+			draft = true
+			chapters = append(chapters, s.chapters(item.Children)...)
+		case item.Type == "ResearchFolder" || scrivWiki[strings.ToLower(item.Title)]:
+			wiki = append(wiki, s.wiki(item.Children)...)
+		}
+	}
+	if !draft {
+		return nil, nil, fmt.Errorf("%s: no Draft folder in the binder", binder)
+	}
+	if len(chapters) == 0 && len(wiki) == 0 {
+		return nil, nil, errors.New("the Scrivener project is empty")
+	}
+	return chapters, wiki, nil
+}
+
+// chapters turns binder items into chapters, descending into parts
+func (s scrivProject) chapters(items []scrivItem) []project.Chapter {
+	var out []project.Chapter
+	for _, item := range items {
+		isPart := false
+		for _, child := range item.Children {
+			isPart = isPart || len(child.Children) > 0 || child.Type == "Folder"
+		}
+		if isPart {
+			// A part's own text, if it has any, is a chapter of its own
+			if text, notes := s.text(item), s.notes(item); text != "" || notes != "" {
+				out = append(out, project.Chapter{Title: item.Title, Content: text, Notes: notes})
+			}
+			out = append(out, s.chapters(item.Children)...)
+			continue
+		}
+
+		chapter := project.Chapter{Title: item.Title}
+		var scenes, notes []string
+		if text := s.text(item); text != "" {
+			scenes = append(scenes, text)
+		}
+		if n := s.notes(item); n != "" {
+			notes = append(notes, n)
+		}
+		for _, scene := range item.Children {
+			if text := s.text(scene); text != "" {
+				scenes = append(scenes, text)
+			}
+			// Scene notes are headed with the scene's title
+			if n := s.notes(scene); n != "" {
+				notes = append(notes, scene.Title+"\n"+n)
+			}
+		}
+		chapter.Content = strings.Join(scenes, "\n\n"+SceneBreak+"\n\n")
+		chapter.Notes = strings.Join(notes, "\n\n")
+		out = append(out, chapter)
+	}
+	return out
+}
+
+// wiki turns the documents of a research folder into wiki entries. Files
+// other than text, such as PDFs and images, are left out unless they have
+// a synopsis or notes.
+func (s scrivProject) wiki(items []scrivItem) []project.WikiEntry {
+	var out []project.WikiEntry
+	for _, item := range items {
+		var parts []string
+		if text := s.text(item); text != "" {
+			parts = append(parts, text)
+		}
+		if notes := s.notes(item); notes != "" {
+			parts = append(parts, notes)
+		}
+		if len(parts) > 0 {
+			out = append(out, project.WikiEntry{Title: item.Title, Content: strings.Join(parts, "\n\n")})
+		}
+		out = append(out, s.wiki(item.Children)...)
+	}
+	return out
+}
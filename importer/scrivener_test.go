@@ -0,0 +1,125 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gowrite/project"
+)
+
+func TestRTFText(t *testing.T) {
+	rtf := `{\rtf1\ansi\ansicpg1252\cocoartf2639
+{\fonttbl\f0\fswiss\fcharset0 Helvetica;}
+{\colortbl;\red255\green255\blue255;}
+{\*\expandedcolortbl;;}
+\pard\tx560\pardirnatural\partightenfactor0
+
+\f0\fs24 \cf0 It was \i very\i0  dark\'85 \b loud\b0 .\
+Caf\'e9 \'93quoted\'94 \{braces\}\par
+{\field{\*\fldinst HYPERLINK "http://x"}{\fldrslt link}} and \u-10179?\u-8694?\par
+}`
+	want := "It was *very* dark… **loud**.\n\nCafé “quoted” {braces}\n\nlink and 😊"
+	if got := rtfText([]byte(rtf)); got != want {
+		t.Errorf("rtfText:\n got %q\nwant %q", got, want)
+	}
+}
+
+// writeScrivener lays out a Scrivener 3 project: the binder and, for each
+// item, its files
+func writeScrivener(t *testing.T, binder string, files map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "Book.scriv")
+	files["Book.scrivx"] = binder
+	for name, body := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestScrivener(t *testing.T) {
+	rtf := func(text string) string { return `{\rtf1\ansi ` + text + `}` }
+	dir := writeScrivener(t, `<?xml version="1.0" encoding="UTF-8"?>
+<ScrivenerProject Version="2.0">
+<Binder>
+  <BinderItem UUID="D" Type="DraftFolder"><Title>Draft</Title><Children>
+    <BinderItem UUID="P" Type="Folder"><Title>Part One</Title><Children>
+      <BinderItem UUID="C1" Type="Folder"><Title>Arrival</Title><Children>
+        <BinderItem UUID="S1" Type="Text"><Title>Station</Title></BinderItem>
+        <BinderItem UUID="S2" Type="Text"><Title>Hotel</Title></BinderItem>
+      </Children></BinderItem>
+    </Children></BinderItem>
+    <BinderItem UUID="C2" Type="Text"><Title>Epilogue</Title></BinderItem>
+  </Children></BinderItem>
+  <BinderItem UUID="R" Type="ResearchFolder"><Title>Research</Title><Children>
+    <BinderItem UUID="R1" Type="Text"><Title>Trains</Title></BinderItem>
+    <BinderItem UUID="R2" Type="PDF"><Title>Timetable</Title></BinderItem>
+  </Children></BinderItem>
+  <BinderItem UUID="CH" Type="Folder"><Title>Characters</Title><Children>
+    <BinderItem UUID="H" Type="Text"><Title>Holmes</Title></BinderItem>
+  </Children></BinderItem>
+  <BinderItem UUID="T" Type="TrashFolder"><Title>Trash</Title><Children>
+    <BinderItem UUID="X" Type="Text"><Title>Cut</Title></BinderItem>
+  </Children></BinderItem>
+</Binder>
+</ScrivenerProject>`, map[string]string{
+		"Files/Data/S1/content.rtf":  rtf(`The train \i hissed\i0 .\par Steam.`),
+		"Files/Data/S1/synopsis.txt": "They arrive.",
+		"Files/Data/S2/content.rtf":  rtf(`A bell rang.`),
+		"Files/Data/S2/notes.rtf":    rtf(`Check the year.`),
+		"Files/Data/C2/content.rtf":  rtf(`The end.`),
+		"Files/Data/R1/content.rtf":  rtf(`Steam engines.`),
+		"Files/Data/H/content.rtf":   rtf(`A detective.`),
+		"Files/Data/H/synopsis.txt":  "Lead.",
+		"Files/Data/X/content.rtf":   rtf(`Gone.`),
+	})
+
+	chapters, wiki, err := Scrivener(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantChapters := []project.Chapter{
+		{Title: "Arrival", Content: "The train *hissed*.\n\nSteam.\n\n* * *\n\nA bell rang.", Notes: "Station\nThey arrive.\n\nHotel\nCheck the year."},
+		{Title: "Epilogue", Content: "The end."},
+	}
+	if !reflect.DeepEqual(chapters, wantChapters) {
+		t.Errorf("chapters:\n got %q\nwant %q", chapters, wantChapters)
+	}
+	wantWiki := []project.WikiEntry{
+		{Title: "Trains", Content: "Steam engines."},
+		{Title: "Holmes", Content: "A detective.\n\nLead."},
+	}
+	if !reflect.DeepEqual(wiki, wantWiki) {
+		t.Errorf("wiki:\n got %q\nwant %q", wiki, wantWiki)
+	}
+
+	// The binder file itself works as well as the folder
+	if c, _, err := Scrivener(filepath.Join(dir, "Book.scrivx")); err != nil || len(c) != 2 {
+		t.Errorf("Scrivener(binder) = %d chapters, %v", len(c), err)
+	}
+	if _, _, err := Scrivener(t.TempDir()); err == nil {
+		t.Error("a folder without a binder did not fail")
+	}
+}
+
+func TestScrivener_Version2(t *testing.T) {
+	dir := writeScrivener(t, `<ScrivenerProject><Binder>
+<BinderItem ID="0" Type="DraftFolder"><Title>Manuscript</Title><Children>
+  <BinderItem ID="5" Type="Text"><Title>One</Title></BinderItem>
+</Children></BinderItem>
+</Binder></ScrivenerProject>`, map[string]string{
+		"Files/Docs/5.rtf":          `{\rtf1 First.}`,
+		"Files/Docs/5_synopsis.txt": "Opening.",
+	})
+	chapters, _, err := Scrivener(dir)
+	if err != nil || len(chapters) != 1 || chapters[0].Content != "First." || chapters[0].Notes != "Opening." {
+		t.Errorf("Scrivener = %q, %v", chapters, err)
+	}
+}
@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+
+	"gowrite/project"
+)
+
+var (
+	headingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	fenceRegex   = regexp.MustCompile("^\\s*(```|~~~)")
+	// notesRegex matches an HTML comment or a pandoc "::: notes" section,
+	// which runs to its closing ":::" or the end of the chapter
+	notesRegex  = regexp.MustCompile(`(?ms)<!--(.*?)-->|^[ \t]*:{3,}[ \t]*\{?\.?notes\}?[ \t]*\n(.*?)(?:^[ \t]*:{3,}[ \t]*$|\z)`)
+	blanksRegex = regexp.MustCompile(`\n{3,}`)
+)
+
+// Split cuts a manuscript into chapters at its headings. Without a pattern
+// it splits on Markdown headings of the shallowest level used more than
+// once, so a single "# Title" over "## Chapter" headings is left alone.
+// Headings inside code fences are ignored.
+func Split(text string, opt Options) []project.Chapter {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	// Find the heading lines
+	type heading struct {
+		line, level int
+		title       string
+	}
+	var headings []heading
+	fenced := false
+	for i, line := range lines {
+		if fenceRegex.MatchString(line) {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+		if opt.Pattern != nil {
+			if title, ok := opt.title(line); ok {
+				headings = append(headings, heading{line: i, title: title})
+			}
+		} else if m := headingRegex.FindStringSubmatch(line); m != nil {
+			headings = append(headings, heading{line: i, level: len(m[1]), title: m[2]})
+		}
+	}
+
+	if opt.Pattern == nil {
+		var levels []int
+		for _, h := range headings {
+			levels = append(levels, h.level)
+		}
+		level := splitLevel(levels)
+		var kept []heading
+		for _, h := range headings {
+			if h.level == level {
+				kept = append(kept, h)
+			}
+		}
+		headings = kept
+	}
+
+	var chapters []project.Chapter
+	add := func(title string, body []string) {
+		content, notes := strings.Join(body, "\n"), ""
+		if opt.Notes {
+			content, notes = extractNotes(content)
+		}
+		content = strings.Trim(content, "\n")
+		if title == FrontMatter && strings.TrimSpace(content) == "" && notes == "" {
+			return
+		}
+		chapters = append(chapters, project.Chapter{Title: title, Content: content, Notes: notes})
+	}
+
+	if len(headings) == 0 || headings[0].line > 0 {
+		end := len(lines)
+		if len(headings) > 0 {
+			end = headings[0].line
+		}
+		add(FrontMatter, lines[:end])
+	}
+	for n, h := range headings {
+		end := len(lines)
+		if n+1 < len(headings) {
+			end = headings[n+1].line
+		}
+		add(h.title, lines[h.line+1:end])
+	}
+	return chapters
+}
+
+// splitLevel picks the heading level to split at: the shallowest used more
+// than once, or the shallowest of all
+func splitLevel(levels []int) int {
+	var count [7]int
+	for _, l := range levels {
+		count[l]++
+	}
+	best, shallowest := 0, 0
+	for l := 1; l <= 6; l++ {
+		if count[l] == 0 {
+			continue
+		}
+		if shallowest == 0 {
+			shallowest = l
+		}
+		if count[l] > 1 && best == 0 {
+			best = l
+		}
+	}
+	if best == 0 {
+		return shallowest
+	}
+	return best
+}
+
+// extractNotes takes HTML comments and ::: notes sections out of text and
+// returns them, a paragraph each, as notes
+func extractNotes(text string) (content, notes string) {
+	var found []string
+	text = notesRegex.ReplaceAllStringFunc(text, func(m string) string {
+		sub := notesRegex.FindStringSubmatch(m)
+		if note := strings.TrimSpace(sub[1] + sub[2]); note != "" {
+			found = append(found, note)
+		}
+		return ""
+	})
+
+	// Lines that only held notes leave runs of blank lines behind
+	content = blanksRegex.ReplaceAllString(text, "\n\n")
+	return content, strings.Join(found, "\n\n")
+}
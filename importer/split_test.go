@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gowrite/project"
+)
+
+func TestSplit_Headings(t *testing.T) {
+	text := `# The Call
+
+A novel.
+
+## One
+
+It was raining.
+
+` + "```" + `
+## Not a chapter
+` + "```" + `
+
+## Two ##
+
+It stopped.
+`
+	want := []project.Chapter{
+		{Title: FrontMatter, Content: "# The Call\n\nA novel."},
+		{Title: "One", Content: "It was raining.\n\n```\n## Not a chapter\n```"},
+		{Title: "Two", Content: "It stopped."},
+	}
+	if got := Split(text, Options{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Split:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestSplit_Pattern(t *testing.T) {
+	text := "Chapter 1\nDawn.\nChapter 2: Dusk\nNight fell.\nChapter two\n"
+
+	got := Split(text, Options{Pattern: regexp.MustCompile(`^Chapter \d+`)})
+	if len(got) != 2 || got[1].Title != "Chapter 2: Dusk" || got[1].Content != "Night fell.\nChapter two" {
+		t.Errorf("Split = %q", got)
+	}
+
+	// A group picks the title out of the heading
+	got = Split(text, Options{Pattern: regexp.MustCompile(`^Chapter \d+:?\s*(.*)`)})
+	if len(got) != 2 || got[0].Title != "Chapter 1" || got[1].Title != "Dusk" {
+		t.Errorf("Split with a group = %q", got)
+	}
+}
+
+func TestSplit_Notes(t *testing.T) {
+	text := `# One
+
+<!-- check the dates -->
+It was raining.
+
+::: notes
+Rain is a metaphor.
+:::
+
+<!--
+Cut the
+weather?
+-->
+The end.`
+
+	got := Split(text, Options{Notes: true})
+	want := []project.Chapter{{
+		Title:   "One",
+		Content: "It was raining.\n\nThe end.",
+		Notes:   "check the dates\n\nRain is a metaphor.\n\nCut the\nweather?",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Split:\n got %q\nwant %q", got, want)
+	}
+
+	if got := Split(text, Options{}); got[0].Notes != "" || got[0].Content == want[0].Content {
+		t.Error("notes extracted without Options.Notes")
+	}
+}
+
+func TestSplit_NoHeadings(t *testing.T) {
+	got := Split("Just prose.\n", Options{})
+	if len(got) != 1 || got[0].Title != FrontMatter || got[0].Content != "Just prose." {
+		t.Errorf("Split = %q", got)
+	}
+	if got := Split("\n\n", Options{}); len(got) != 0 {
+		t.Errorf("Split of blank text = %q", got)
+	}
+}
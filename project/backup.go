@@ -0,0 +1,233 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Backup defaults used when a project has no BackupPolicy of its own
+const (
+	DefaultBackupKeep     = 20
+	DefaultBackupInterval = 10 // minutes
+)
+
+// Timestamp formats in backup file names. Seconds are only added when a
+// backup for the same minute already exists.
+const (
+	backupStamp        = "20060102-1504"
+	backupStampSeconds = "20060102-150405"
+)
+
+// now is replaced in tests
+var now = time.Now
+
+// ErrNotSaved is returned for operations that need a project file on disk
+var ErrNotSaved = errors.New("project has not been saved yet")
+
+// BackupPolicy controls the timestamped copies Save keeps of the previous
+// version of a project file in .gowrite/backups
+type BackupPolicy struct {
+	Keep     int // newest backups kept; 0 turns backups off
+	Interval int // minimum minutes between backups; 0 backs up on every save
+}
+
+// Backup is one saved copy of a project file
+type Backup struct {
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// Policy returns the project's backup policy, or the defaults
+func (p *Project) Policy() BackupPolicy {
+	if p.Backups == nil {
+		return BackupPolicy{Keep: DefaultBackupKeep, Interval: DefaultBackupInterval}
+	}
+	return *p.Backups
+}
+
+// backupDir is where backups of the project at projectPath are stored
+func backupDir(projectPath string) string {
+	return filepath.Dir(sidePath(projectPath, "backups", ""))
+}
+
+// backupName returns an unused backup file name for projectPath taken at t
+func backupName(projectPath string, t time.Time) string {
+	name := sidePath(projectPath, "backups", "-"+t.Format(backupStamp)+".json")
+	if _, err := os.Stat(name); err == nil {
+		name = sidePath(projectPath, "backups", "-"+t.Format(backupStampSeconds)+".json")
+	}
+	return name
+}
+
+// ListBackups returns the backups of the project at projectPath, newest first
+func ListBackups(projectPath string) ([]Backup, error) {
+	entries, err := os.ReadDir(backupDir(projectPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stampRe := regexp.MustCompile(`^` + regexp.QuoteMeta(projectName(projectPath)) + `-(\d{8}-\d{4}(\d{2})?)\.json$`)
+
+	var backups []Backup
+	for _, e := range entries {
+		m := stampRe.FindStringSubmatch(e.Name())
+		if m == nil || e.IsDir() {
+			continue
+		}
+		layout := backupStamp
+		if m[2] != "" {
+			layout = backupStampSeconds
+		}
+		t, err := time.ParseInLocation(layout, m[1], time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{
+			Path: filepath.Join(backupDir(projectPath), e.Name()),
+			Time: t,
+			Size: info.Size(),
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Time.After(backups[j].Time) })
+	return backups, nil
+}
+
+// backup copies the file at filename into the backup directory before it
+// is overwritten, then prunes old backups. Unless force is set, nothing is
+// copied when the newest backup is younger than the policy interval.
+func (p *Project) backup(filename string, force bool) error {
+	policy := p.Policy()
+	if policy.Keep <= 0 {
+		return nil
+	}
+
+	current, err := snapshot(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil // first save, nothing to back up
+	}
+	if err != nil {
+		return err
+	}
+
+	backups, err := ListBackups(filename)
+	if err != nil {
+		return err
+	}
+	t := now()
+	if !force && len(backups) > 0 && t.Sub(backups[0].Time) < time.Duration(policy.Interval)*time.Minute {
+		return nil
+	}
+
+	if err := os.MkdirAll(backupDir(filename), 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(backupName(filename, t), current, 0644); err != nil {
+		return err
+	}
+
+	backups, err = ListBackups(filename)
+	if err != nil {
+		return err
+	}
+	for i := policy.Keep; i < len(backups); i++ {
+		os.Remove(backups[i].Path)
+	}
+	return nil
+}
+
+// snapshot returns the project file at filename as backed up. A directory
+// project is backed up as a single JSON file.
+func snapshot(filename string) ([]byte, error) {
+	if !IsDir(filename) {
+		return os.ReadFile(filename)
+	}
+	p, err := loadDir(filename)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// RestoreBackup makes b the current version of the project at projectPath.
+// The version being replaced is backed up first, so a restore can itself
+// be undone from the backup list.
+func RestoreBackup(projectPath string, b Backup) (*Project, error) {
+	if projectPath == "" {
+		return nil, ErrNotSaved
+	}
+	data, err := os.ReadFile(b.Path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if current, err := Load(projectPath); err == nil {
+		// Keep the policy of the file being replaced
+		p.Backups = current.Backups
+	}
+	if err := p.backup(projectPath, true); err != nil {
+		return nil, err
+	}
+	if err := p.write(projectPath); err != nil {
+		return nil, err
+	}
+	p.Filename = projectPath
+	return p, nil
+}
+
+// writeFileAtomic replaces filename with data so that a crash leaves either
+// the old or the new file, never a partial one: data goes to a temporary
+// file in the same directory, is synced to disk and then renamed into place.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return err
+	}
+
+	// Persist the rename itself; not every platform can sync a directory
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clock makes now() return t, advanced by step on every call
+func clock(t *testing.T, start time.Time, step time.Duration) {
+	t.Helper()
+	current := start
+	now = func() time.Time {
+		current = current.Add(step)
+		return current
+	}
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestSave_Atomic(t *testing.T) {
+	dir := t.TempDir()
+	p := New()
+	if err := p.Save(filepath.Join(dir, "book")); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("temporary file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestSave_Backups(t *testing.T) {
+	clock(t, time.Date(2026, 10, 17, 15, 0, 0, 0, time.Local), 5*time.Minute)
+	name := filepath.Join(t.TempDir(), "book.json")
+
+	p := New()
+	p.Backups = &BackupPolicy{Keep: 2, Interval: 10}
+	for i := 0; i < 6; i++ {
+		p.Chapters[0].Content = strings.Repeat("x", i)
+		if err := p.Save(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first save has nothing to back up; the clock then reads 15:05,
+	// 15:10, ... on each later save and the 10 minute interval skips every
+	// other one, leaving 15:05, 15:15 and 15:25 of which the newest two stay
+	backups, err := ListBackups(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("got %d backups, want 2: %+v", len(backups), backups)
+	}
+	if got := backups[0].Time.Format("1504"); got != "1525" {
+		t.Errorf("newest backup at %s, want 1525", got)
+	}
+	if filepath.Base(backups[0].Path) != "book-20261017-1525.json" {
+		t.Errorf("backup name = %s", filepath.Base(backups[0].Path))
+	}
+
+	// The backup holds the version before the save that made it
+	old, err := Load(backups[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Chapters[0].Content != "xxxx" {
+		t.Errorf("backup content = %q, want %q", old.Chapters[0].Content, "xxxx")
+	}
+}
+
+func TestSave_BackupsDisabled(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "book.json")
+	p := New()
+	p.Backups = &BackupPolicy{Keep: 0}
+	p.Save(name)
+	p.Save(name)
+	if backups, _ := ListBackups(name); len(backups) != 0 {
+		t.Errorf("got %d backups with Keep 0", len(backups))
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	clock(t, time.Date(2026, 10, 17, 15, 0, 0, 0, time.Local), time.Hour)
+	name := filepath.Join(t.TempDir(), "book.json")
+
+	p := New()
+	p.Chapters[0].Content = "first draft"
+	p.Save(name)
+	p.Chapters[0].Content = "second draft"
+	p.Save(name)
+
+	backups, _ := ListBackups(name)
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups, want 1", len(backups))
+	}
+	restored, err := RestoreBackup(name, backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Chapters[0].Content != "first draft" || restored.Filename != name {
+		t.Errorf("restored = %+v", restored)
+	}
+
+	onDisk, _ := Load(name)
+	if onDisk.Chapters[0].Content != "first draft" {
+		t.Errorf("file content = %q after restore", onDisk.Chapters[0].Content)
+	}
+	// The replaced version is itself backed up
+	backups, _ = ListBackups(name)
+	if len(backups) != 2 {
+		t.Fatalf("got %d backups after restore, want 2", len(backups))
+	}
+	replaced, _ := Load(backups[0].Path)
+	if replaced.Chapters[0].Content != "second draft" {
+		t.Errorf("newest backup = %q, want the replaced version", replaced.Chapters[0].Content)
+	}
+}
+
+func TestBackup_SameMinute(t *testing.T) {
+	clock(t, time.Date(2026, 10, 17, 15, 0, 0, 0, time.Local), time.Second)
+	name := filepath.Join(t.TempDir(), "book.json")
+
+	p := New()
+	p.Backups = &BackupPolicy{Keep: 5, Interval: 0}
+	for i := 0; i < 3; i++ {
+		if err := p.Save(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, _ := ListBackups(name)
+	if len(backups) != 2 {
+		t.Fatalf("got %d backups, want 2 (no overwrite within a minute)", len(backups))
+	}
+	if filepath.Base(backups[0].Path) != "book-20261017-150002.json" {
+		t.Errorf("second backup in a minute = %s", filepath.Base(backups[0].Path))
+	}
+}
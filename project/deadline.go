@@ -0,0 +1,89 @@
+package project
+
+import (
+	"errors"
+	"time"
+)
+
+// DeadlineFormat is how Project.Deadline is written
+const DeadlineFormat = "2006-01-02"
+
+// recentDays is how far back Plan looks for the pace of writing
+const recentDays = 14
+
+// ErrNoDeadline is returned by Plan for a project without a deadline and a
+// manuscript target
+var ErrNoDeadline = errors.New("no deadline set: 'deadline <yyyy-mm-dd> <words>'")
+
+// Plan is where a manuscript stands against its deadline
+type Plan struct {
+	Deadline  time.Time
+	DaysLeft  int // counting today and the deadline itself; 0 once it has passed
+	Words     int // in the manuscript now
+	Remaining int // words still to write, as of the start of today
+	PerDay    int // words a day needed to finish on time
+	Today     int // words written today
+
+	// Pace is the words written a day over the last two weeks. At that pace
+	// the manuscript is done on Projected, which is zero without any pace.
+	Pace      int
+	Projected time.Time
+}
+
+// ParseDeadline reads a deadline date in DeadlineFormat, in local time
+func ParseDeadline(s string) (time.Time, error) {
+	return time.ParseInLocation(DeadlineFormat, s, time.Local)
+}
+
+// Plan works out the words a day the manuscript, now words long, needs to
+// reach p.Target by p.Deadline, from h. The daily figure is fixed at the
+// start of each day, so writing today does not move today's goal.
+func (p *Project) Plan(h *History, words int) (Plan, error) {
+	if p.Deadline == "" || p.Target <= 0 {
+		return Plan{}, ErrNoDeadline
+	}
+	deadline, err := ParseDeadline(p.Deadline)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	today := day(now())
+	plan := Plan{Deadline: deadline, Words: words, Today: h.Today()}
+	plan.DaysLeft = max(int(deadline.Sub(today).Hours()/24+0.5)+1, 0)
+	plan.Remaining = max(p.Target-(words-plan.Today), 0)
+	if plan.DaysLeft > 0 {
+		plan.PerDay = (plan.Remaining + plan.DaysLeft - 1) / plan.DaysLeft
+	} else {
+		plan.PerDay = plan.Remaining
+	}
+
+	// Pace over the last two weeks, or since writing began if that is sooner
+	since := today.AddDate(0, 0, -(recentDays - 1))
+	written, first := 0, today
+	for _, d := range h.Days() {
+		date, err := time.ParseInLocation(dayFormat, d.Date, time.Local)
+		if err != nil || date.Before(since) {
+			continue
+		}
+		written += d.Words
+		if date.Before(first) {
+			first = date
+		}
+	}
+	plan.Pace = written / (int(today.Sub(first).Hours()/24+0.5) + 1)
+
+	left := p.Target - words
+	switch {
+	case left <= 0:
+		plan.Projected = today
+	case plan.Pace > 0:
+		plan.Projected = today.AddDate(0, 0, (left+plan.Pace-1)/plan.Pace)
+	}
+	return plan, nil
+}
+
+// day returns the start of t's day
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,50 @@
+package project
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPlan(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 9, 0, 0, 0, time.Local) }
+	session := func(start time.Time, words int) Session {
+		return Session{Start: start, End: start.Add(time.Hour), Chapters: []ChapterWords{{Title: "One", Added: words}}}
+	}
+	h := &History{Sessions: []Session{
+		session(day(9, 1), 3000), // too long ago to count towards the pace
+		session(day(10, 13), 400),
+		session(day(10, 15), 600),
+		session(day(10, 16), 800),
+		session(day(10, 17), 200),
+	}}
+	now = func() time.Time { return day(10, 17).Add(3 * time.Hour) }
+	t.Cleanup(func() { now = time.Now })
+
+	p := New()
+	if _, err := p.Plan(h, 5200); !errors.Is(err, ErrNoDeadline) {
+		t.Errorf("Plan() without a deadline error = %v", err)
+	}
+
+	p.Target, p.Deadline = 10000, "2026-10-26"
+	plan, err := p.Plan(h, 5200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 5000 words to go this morning, over 10 days; 2000 words in 5 days
+	if plan.DaysLeft != 10 || plan.Remaining != 5000 || plan.PerDay != 500 || plan.Today != 200 {
+		t.Errorf("Plan() = %+v", plan)
+	}
+	if plan.Pace != 400 || plan.Projected.Format(DeadlineFormat) != "2026-10-29" {
+		t.Errorf("Pace = %d, Projected = %v", plan.Pace, plan.Projected)
+	}
+
+	p.Deadline = "2026-10-01"
+	if plan, _ := p.Plan(h, 5200); plan.DaysLeft != 0 || plan.PerDay != 5000 {
+		t.Errorf("Plan() past the deadline = %+v", plan)
+	}
+	p.Deadline = "31/12/2026"
+	if _, err := p.Plan(h, 5200); err == nil {
+		t.Error("Plan() accepted a malformed deadline")
+	}
+}
@@ -0,0 +1,277 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ManifestFile names the manifest of a directory project. A directory
+// project keeps each chapter, its notes and each wiki entry in a Markdown
+// file of its own, so changes diff and merge well under version control:
+//
+//	mybook/
+//	  gowrite.json               order, titles, targets and settings
+//	  chapters/the-beginning.md
+//	  notes/the-beginning.md     only for chapters with notes
+//	  wiki/general-notes.md
+//
+// File names follow the titles, numbered when two are alike, and a file
+// keeps its name for as long as it fits the title. The order lives in the
+// manifest alone, so reordering chapters never renames a file.
+const ManifestFile = "gowrite.json"
+
+// manifest is the JSON stored in ManifestFile. The embedded Project carries
+// every project-level field; Chapters and Wiki shadow its own so that the
+// text moves out into files.
+type manifest struct {
+	*Project
+	Chapters []manifestChapter
+	Wiki     []manifestEntry
+}
+
+// manifestChapter is a Chapter whose text lives in files
+type manifestChapter struct {
+	Title  string
+	Target int `json:",omitempty"`
+	File   string
+	Notes  string `json:",omitempty"`
+}
+
+// manifestEntry is a WikiEntry whose text lives in a file
+type manifestEntry struct {
+	Title string
+	File  string
+}
+
+// IsDir reports whether path names a directory project: it ends in a path
+// separator, is an existing directory, or is a directory's manifest
+func IsDir(path string) bool {
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(filepath.Separator)) {
+		return true
+	}
+	if filepath.Base(path) == ManifestFile {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// IsProjectDir reports whether dir holds a directory project
+func IsProjectDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ManifestFile))
+	return err == nil
+}
+
+// dirName normalises a directory project path to end in a separator
+func dirName(path string) string {
+	if filepath.Base(path) == ManifestFile {
+		path = filepath.Dir(path)
+	}
+	return filepath.Clean(path) + string(filepath.Separator)
+}
+
+// loadDir reads the directory project at dir
+func loadDir(dir string) (*Project, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	version, err := formatVersion(data)
+	if err != nil {
+		return nil, ErrEmpty
+	}
+	if version > FormatVersion {
+		return nil, fmt.Errorf("%w (format %d, this gowrite reads up to %d)", ErrNewerVersion, version, FormatVersion)
+	}
+
+	m := manifest{Project: &Project{}}
+	if err := json.Unmarshal(data, &m); err != nil || len(m.Chapters) == 0 {
+		return nil, ErrEmpty
+	}
+	read := func(name string) (string, error) {
+		if name == "" {
+			return "", nil
+		}
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return "", fmt.Errorf("%s: file outside the project", name)
+		}
+		text, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		return string(text), err
+	}
+
+	p := m.Project
+	p.Version = FormatVersion
+	p.Chapters = nil
+	for _, c := range m.Chapters {
+		content, err := read(c.File)
+		if err != nil {
+			return nil, err
+		}
+		notes, err := read(c.Notes)
+		if err != nil {
+			return nil, err
+		}
+		p.Chapters = append(p.Chapters, Chapter{Title: c.Title, Content: content, Notes: notes, Target: c.Target, file: c.File, notesFile: c.Notes})
+	}
+	p.Wiki = nil
+	for _, w := range m.Wiki {
+		content, err := read(w.File)
+		if err != nil {
+			return nil, err
+		}
+		p.Wiki = append(p.Wiki, WikiEntry{Title: w.Title, Content: content, file: w.File})
+	}
+	if len(p.Wiki) == 0 {
+		p.Wiki = []WikiEntry{{Title: "General"}}
+	}
+	return p, nil
+}
+
+// writeDir stores the project as a directory project at dir. Every file is
+// replaced atomically, the manifest last, and files the previous manifest
+// listed but the new one does not are removed.
+func (p *Project) writeDir(dir string) error {
+	old := manifest{Project: &Project{}}
+	if data, err := os.ReadFile(filepath.Join(dir, ManifestFile)); err == nil {
+		json.Unmarshal(data, &old)
+	}
+
+	m := manifest{Project: p}
+	used := make(map[string]bool)
+
+	// Files that still fit their titles are claimed first, so that a new
+	// chapter cannot take the name of one further down
+	keep := func(kind, title, file string) string {
+		if file == "" || used[file] || !fits(kind, slug(title), file) {
+			return ""
+		}
+		used[file] = true
+		return file
+	}
+	files := make([]manifestChapter, len(p.Chapters))
+	for i, c := range p.Chapters {
+		files[i].File = keep("chapters", c.Title, c.file)
+		if c.Notes != "" {
+			files[i].Notes = keep("notes", c.Title, c.notesFile)
+		}
+	}
+	wikiFiles := make([]string, len(p.Wiki))
+	for i, w := range p.Wiki {
+		wikiFiles[i] = keep("wiki", w.Title, w.file)
+	}
+
+	write := func(kind, title, name, text string) (string, error) {
+		if name == "" {
+			name = uniqueName(kind, slug(title), used)
+		}
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		if current, err := os.ReadFile(full); err == nil && string(current) == text {
+			return name, nil // unchanged, leave the file alone
+		}
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			return "", err
+		}
+		return name, writeFileAtomic(full, []byte(text), 0644)
+	}
+
+	for i := range p.Chapters {
+		c := &p.Chapters[i]
+		mc := manifestChapter{Title: c.Title, Target: c.Target}
+		var err error
+		if mc.File, err = write("chapters", c.Title, files[i].File, c.Content); err != nil {
+			return err
+		}
+		if c.Notes != "" {
+			if mc.Notes, err = write("notes", c.Title, files[i].Notes, c.Notes); err != nil {
+				return err
+			}
+		}
+		c.file, c.notesFile = mc.File, mc.Notes
+		m.Chapters = append(m.Chapters, mc)
+	}
+	for i := range p.Wiki {
+		w := &p.Wiki[i]
+		file, err := write("wiki", w.Title, wikiFiles[i], w.Content)
+		if err != nil {
+			return err
+		}
+		w.file = file
+		m.Wiki = append(m.Wiki, manifestEntry{Title: w.Title, File: file})
+	}
+
+	p.Version = FormatVersion
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, ManifestFile), data, 0644); err != nil {
+		return err
+	}
+
+	var stale []string
+	for _, c := range old.Chapters {
+		stale = append(stale, c.File, c.Notes)
+	}
+	for _, w := range old.Wiki {
+		stale = append(stale, w.File)
+	}
+	for _, name := range stale {
+		if name != "" && !used[name] && filepath.IsLocal(filepath.FromSlash(name)) {
+			if err := os.Remove(filepath.Join(dir, filepath.FromSlash(name))); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// slug turns a title into a file name: "Chapter 1: The Call" → "chapter-1-the-call"
+func slug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "untitled"
+	}
+	return b.String()
+}
+
+// fits reports whether file is a name uniqueName could give base
+func fits(kind, base, file string) bool {
+	stem, md := strings.CutSuffix(file, ".md")
+	stem, ok := strings.CutPrefix(stem, kind+"/")
+	if !md || !ok {
+		return false
+	}
+	if stem == base {
+		return true
+	}
+	n, ok := strings.CutPrefix(stem, base+"-")
+	return ok && n != "" && strings.Trim(n, "0123456789") == ""
+}
+
+// uniqueName returns kind/base.md, numbered when the name is taken
+func uniqueName(kind, base string, used map[string]bool) string {
+	name := path.Join(kind, base+".md")
+	for n := 2; used[name]; n++ {
+		name = path.Join(kind, fmt.Sprintf("%s-%d.md", base, n))
+	}
+	used[name] = true
+	return name
+}
@@ -0,0 +1,182 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFilename_Dir(t *testing.T) {
+	dir := t.TempDir()
+	sep := string(filepath.Separator)
+	tests := []struct{ in, want string }{
+		{"book", "book.json"},
+		{"book.json", "book.json"},
+		{"book/", "book" + sep},
+		{filepath.Join("book", ManifestFile), "book" + sep},
+		{dir, dir + sep},
+	}
+	for _, tt := range tests {
+		if got := Filename(tt.in); got != tt.want {
+			t.Errorf("Filename(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDir_SaveLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "book") + "/"
+	p := withChapters("Chapter 1: The Call", "Intro", "intro")
+	p.Chapters[0].Content = "The phone rang at midnight...\n"
+	p.Chapters[0].Notes = "Foreshadow the villain."
+	p.Chapters[0].Target = 1500
+	p.Wiki[0].Content = "Name: John Doe"
+	p.Backups = &BackupPolicy{Keep: 3, Interval: 0}
+	if err := p.Save(dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"chapters/chapter-1-the-call.md": p.Chapters[0].Content,
+		"notes/chapter-1-the-call.md":    p.Chapters[0].Notes,
+		"chapters/intro-2.md":            "",
+		"wiki/general-notes.md":          "Name: John Doe",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes", "intro.md")); !os.IsNotExist(err) {
+		t.Errorf("notes file written for a chapter without notes: %v", err)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !equal(titles(loaded), titles(p)) || loaded.Chapters[0] != p.Chapters[0] {
+		t.Errorf("loaded chapters = %+v", loaded.Chapters)
+	}
+	if loaded.Wiki[0] != p.Wiki[0] || loaded.Policy() != p.Policy() || loaded.Version != FormatVersion {
+		t.Errorf("loaded project = %+v", loaded)
+	}
+	if loaded.Filename != Filename(dir) {
+		t.Errorf("Filename = %q, want %q", loaded.Filename, Filename(dir))
+	}
+}
+
+func TestDir_RenameAndReorder(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "book") + "/"
+	p := withChapters("One", "Two")
+	p.Chapters[0].Notes = "notes"
+	if err := p.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	p.MoveChapter(1, 0)
+	if err := p.Save(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "chapters", "one.md")); err != nil {
+		t.Errorf("reordering renamed a file: %v", err)
+	}
+
+	p.RenameChapter(1, "First")
+	if err := p.Save(""); err != nil {
+		t.Fatal(err)
+	}
+	for _, stale := range []string{"chapters/one.md", "notes/one.md"} {
+		if _, err := os.Stat(filepath.Join(dir, stale)); !os.IsNotExist(err) {
+			t.Errorf("%s left behind after rename: %v", stale, err)
+		}
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal(titles(loaded), []string{"Two", "First"}) || loaded.Chapters[1].Notes != "notes" {
+		t.Errorf("loaded chapters = %+v", loaded.Chapters)
+	}
+}
+
+func TestDir_ReorderSameTitle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "book") + "/"
+	p := withChapters("Interlude", "Interlude")
+	p.Chapters[0].Content, p.Chapters[1].Content = "first", "second"
+	if err := p.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving one past the other, or a new chapter of the same title in
+	// front of both, leaves every file with the text it had
+	p.MoveChapter(1, 0)
+	p.Chapters = append([]Chapter{{Title: "Interlude", Content: "third"}}, p.Chapters...)
+	if err := p.Save(""); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"interlude.md": "first", "interlude-2.md": "second", "interlude-3.md": "third"} {
+		if got, err := os.ReadFile(filepath.Join(dir, "chapters", name)); string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.MoveChapter(2, 1)
+	if err := loaded.Save(""); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "chapters", "interlude.md")); string(got) != "first" {
+		t.Errorf("reordering after a load swapped files: interlude.md = %q", got)
+	}
+	if loaded, _ = Load(dir); loaded.Chapters[1].Content != "first" || loaded.Chapters[2].Content != "second" {
+		t.Errorf("loaded chapters = %+v", loaded.Chapters)
+	}
+}
+
+func TestDir_Backups(t *testing.T) {
+	clock(t, time.Date(2026, 10, 17, 15, 0, 0, 0, time.Local), time.Minute)
+	dir := filepath.Join(t.TempDir(), "book") + "/"
+	p := withChapters("One")
+	p.Backups = &BackupPolicy{Keep: 5}
+	p.Chapters[0].Content = "first draft"
+	if err := p.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	p.Chapters[0].Content = "second draft"
+	if err := p.Save(""); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := ListBackups(p.Filename)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("ListBackups() = %v, %v; want one backup", backups, err)
+	}
+	restored, err := RestoreBackup(p.Filename, backups[0])
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "chapters", "one.md"))
+	if restored.Chapters[0].Content != "first draft" || string(got) != "first draft" {
+		t.Errorf("restored %q, file %q", restored.Chapters[0].Content, got)
+	}
+}
+
+func TestDir_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() of a directory without a manifest error = %v", err)
+	}
+
+	manifest := `{"Version": 2, "Chapters": [{"Title": "Escape", "File": "../secret.md"}]}`
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("Load() read a file outside the project")
+	}
+}
@@ -0,0 +1,98 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FormatVersion is the version of the project file format this gowrite
+// writes. Files from older versions are migrated on load.
+//
+//	0: a bare array of chapters
+//	1: an object with Chapters and Wiki but no Version
+//	2: Version is recorded
+//
+// Optional fields added since, such as Metadata, Mode, Target, Daily and
+// Deadline, leave the version alone: a file without them means what it
+// always did, and an older gowrite can still open one with them. Bump the
+// version only for a change that needs a migration.
+const FormatVersion = 2
+
+// ErrNewerVersion is returned for files written by a newer gowrite, which may
+// hold data this version would lose
+var ErrNewerVersion = errors.New("file was written by a newer version of gowrite")
+
+// migrations[v] upgrades a version v file to version v+1. Migrations work on
+// the raw JSON so fields that no longer exist in Project can still be moved.
+var migrations = []func(data []byte) ([]byte, error){
+	// 0 → 1: wrap the chapter array in a project
+	func(data []byte) ([]byte, error) {
+		var chapters []json.RawMessage
+		if err := json.Unmarshal(data, &chapters); err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]any{"Chapters": chapters})
+	},
+	// 1 → 2: the data is unchanged; the version is stamped on save
+	noMigration,
+}
+
+// noMigration is a migration for a version that left the data as it was
+func noMigration(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// formatVersion reports which version of the file format data is in
+func formatVersion(data []byte) (int, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		return 0, nil
+	}
+	var header struct{ Version *int }
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, err
+	}
+	if header.Version == nil {
+		return 1, nil
+	}
+	return *header.Version, nil
+}
+
+// migrate upgrades data to FormatVersion, returning the version it started at
+func migrate(data []byte) ([]byte, int, error) {
+	version, err := formatVersion(data)
+	if err != nil {
+		return nil, 0, ErrEmpty
+	}
+	if version > FormatVersion {
+		return nil, version, fmt.Errorf("%w (format %d, this gowrite reads up to %d)", ErrNewerVersion, version, FormatVersion)
+	}
+	if version < 0 {
+		return nil, version, ErrEmpty
+	}
+
+	for v := version; v < FormatVersion; v++ {
+		if data, err = migrations[v](data); err != nil {
+			return nil, version, ErrEmpty
+		}
+	}
+	return data, version, nil
+}
+
+// migrationBackup keeps a copy of a file as it was before migration, in
+// .gowrite/backups/<name>-v<version>.json. Unlike timestamped backups it is
+// never pruned, and an existing copy is left alone.
+func migrationBackup(filename string, version int, data []byte) error {
+	path := sidePath(filename, "backups", fmt.Sprintf("-v%d.json", version))
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
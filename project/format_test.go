@@ -0,0 +1,77 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_Migrates(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		version int
+	}{
+		{"chapter array", `[{"Title": "Legacy", "Content": "Old text"}]`, 0},
+		{"unversioned project", `{"Chapters": [{"Title": "Legacy", "Content": "Old text"}], "Wiki": [{"Title": "Cast"}]}`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			name := filepath.Join(dir, "book.json")
+			if err := os.WriteFile(name, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := Load(name)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if p.Version != FormatVersion || p.Chapters[0].Content != "Old text" || len(p.Wiki) == 0 {
+				t.Errorf("Load() = %+v", p)
+			}
+
+			backup, err := os.ReadFile(filepath.Join(dir, ".gowrite", "backups", fmt.Sprintf("book-v%d.json", tt.version)))
+			if err != nil || string(backup) != tt.data {
+				t.Errorf("original not kept: %q, %v", backup, err)
+			}
+
+			if err := p.Save(""); err != nil {
+				t.Fatal(err)
+			}
+			saved, _ := os.ReadFile(name)
+			if !strings.Contains(string(saved), fmt.Sprintf(`"Version": %d`, FormatVersion)) {
+				t.Errorf("saved file has no version:\n%s", saved)
+			}
+		})
+	}
+}
+
+func TestLoad_CurrentNoBackup(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "book.json")
+	if err := New().Save(name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(name); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".gowrite", "backups")); !os.IsNotExist(err) {
+		t.Errorf("current file was backed up on load: %v", err)
+	}
+}
+
+func TestLoad_NewerVersion(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "book.json")
+	data := `{"Version": 99, "Chapters": [{"Title": "From the future"}]}`
+	if err := os.WriteFile(name, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(name); !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("Load() error = %v, want %v", err, ErrNewerVersion)
+	}
+}
@@ -0,0 +1,228 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// dayFormat keys the days of a History
+const dayFormat = "2006-01-02"
+
+// Session is one sitting at the editor: when it ran and the words added to
+// and deleted from each chapter
+type Session struct {
+	Start    time.Time
+	End      time.Time
+	Chapters []ChapterWords `json:",omitempty"`
+
+	// The chapter being followed, see Follow
+	title string
+	words int
+}
+
+// ChapterWords counts the words added to and deleted from one chapter
+type ChapterWords struct {
+	Title   string
+	Added   int
+	Deleted int
+}
+
+// Follow starts counting the chapter being edited from its current number
+// of words. Loading a chapter is not writing it.
+func (s *Session) Follow(title string, words int) {
+	s.title, s.words = title, words
+}
+
+// Count records that the followed chapter now has words. The session
+// starts with its first change.
+func (s *Session) Count(words int) {
+	delta := words - s.words
+	s.words = words
+	if delta == 0 {
+		return
+	}
+	s.End = now()
+	if s.Start.IsZero() {
+		s.Start = s.End
+	}
+	for i := range s.Chapters {
+		if s.Chapters[i].Title == s.title {
+			s.Chapters[i].add(delta)
+			return
+		}
+	}
+	s.Chapters = append(s.Chapters, ChapterWords{Title: s.title})
+	s.Chapters[len(s.Chapters)-1].add(delta)
+}
+
+func (c *ChapterWords) add(delta int) {
+	if delta > 0 {
+		c.Added += delta
+	} else {
+		c.Deleted -= delta
+	}
+}
+
+// Added returns the words added in every chapter
+func (s *Session) Added() int {
+	n := 0
+	for _, c := range s.Chapters {
+		n += c.Added
+	}
+	return n
+}
+
+// Deleted returns the words deleted in every chapter
+func (s *Session) Deleted() int {
+	n := 0
+	for _, c := range s.Chapters {
+		n += c.Deleted
+	}
+	return n
+}
+
+// Words returns the words the session gained: added less deleted
+func (s *Session) Words() int {
+	return s.Added() - s.Deleted()
+}
+
+// History is the record of a project's writing sessions, kept next to it
+// in .gowrite/history
+type History struct {
+	Sessions []Session
+	Sprints  []Sprint `json:",omitempty"`
+}
+
+// Sprint is a timed burst of writing and the words it gained
+type Sprint struct {
+	Start   time.Time
+	End     time.Time // early if the sprint was stopped
+	Minutes int       // as set
+	Words   int
+}
+
+// Finished reports whether the sprint ran its full time
+func (s Sprint) Finished() bool {
+	return s.End.Sub(s.Start) >= time.Duration(s.Minutes)*time.Minute
+}
+
+// Day is the writing done on one calendar day
+type Day struct {
+	Date  string // as 2006-01-02
+	Words int    // added less deleted
+	Time  time.Duration
+}
+
+// LoadHistory reads the history of the project at projectPath. A project
+// never written in has an empty history.
+func LoadHistory(projectPath string) (*History, error) {
+	h := &History{}
+	data, err := os.ReadFile(sidePath(projectPath, "history", ".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// SaveHistory writes the history of the project at projectPath
+func SaveHistory(projectPath string, h *History) error {
+	path := sidePath(projectPath, "history", ".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// Record adds a session to the history, or updates it if it is already
+// there. Sessions without any writing are left out.
+func (h *History) Record(s *Session) {
+	for i := len(h.Sessions) - 1; i >= 0; i-- {
+		if h.Sessions[i].Start.Equal(s.Start) {
+			h.Sessions[i] = *s
+			return
+		}
+	}
+	if len(s.Chapters) > 0 {
+		h.Sessions = append(h.Sessions, *s)
+	}
+}
+
+// Days returns the writing done on each day there was any, oldest first.
+// A session counts on the day it started.
+func (h *History) Days() []Day {
+	byDate := map[string]*Day{}
+	var days []Day
+	for _, s := range h.Sessions {
+		date := s.Start.Local().Format(dayFormat)
+		d := byDate[date]
+		if d == nil {
+			d = &Day{Date: date}
+			byDate[date] = d
+		}
+		d.Words += s.Words()
+		d.Time += s.End.Sub(s.Start)
+	}
+	for _, d := range byDate {
+		days = append(days, *d)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
+
+// Today returns the words written today
+func (h *History) Today() int {
+	days := h.Days()
+	if len(days) > 0 && days[len(days)-1].Date == now().Format(dayFormat) {
+		return days[len(days)-1].Words
+	}
+	return 0
+}
+
+// Streak counts the days in a row, up to today, on which at least goal
+// words were written (any at all without a goal). A streak is not broken
+// until today is over.
+func (h *History) Streak(goal int) int {
+	done := map[string]bool{}
+	for _, d := range h.Days() {
+		done[d.Date] = d.Words >= max(goal, 1)
+	}
+	day := now()
+	if !done[day.Format(dayFormat)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	streak := 0
+	for done[day.Format(dayFormat)] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// Pace returns the words written per hour across all sessions, 0 before
+// there is a minute of writing to go on
+func (h *History) Pace() int {
+	var words int
+	var spent time.Duration
+	for _, s := range h.Sessions {
+		words += s.Words()
+		spent += s.End.Sub(s.Start)
+	}
+	if spent < time.Minute {
+		return 0
+	}
+	return int(float64(words) / spent.Hours())
+}
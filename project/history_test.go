@@ -0,0 +1,96 @@
+package project
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSession(t *testing.T) {
+	clock(t, time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local), time.Minute)
+	s := &Session{}
+	s.Follow("One", 100) // loading a chapter writes nothing
+	if !s.Start.IsZero() || len(s.Chapters) != 0 {
+		t.Fatalf("following a chapter started the session: %+v", s)
+	}
+	s.Count(130)
+	s.Count(120)
+	s.Follow("Two", 0)
+	s.Count(50)
+	s.Follow("One", 120)
+	s.Count(125)
+
+	want := []ChapterWords{{"One", 35, 10}, {"Two", 50, 0}}
+	if len(s.Chapters) != 2 || s.Chapters[0] != want[0] || s.Chapters[1] != want[1] {
+		t.Errorf("Chapters = %+v, want %+v", s.Chapters, want)
+	}
+	if s.Words() != 75 || s.End.Sub(s.Start) != 3*time.Minute {
+		t.Errorf("Words() = %d, ran %v", s.Words(), s.End.Sub(s.Start))
+	}
+}
+
+func TestHistory(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2026, 10, d, hour, 0, 0, 0, time.Local) }
+	session := func(start time.Time, words int) Session {
+		return Session{Start: start, End: start.Add(30 * time.Minute), Chapters: []ChapterWords{{Title: "One", Added: words}}}
+	}
+	h := &History{Sessions: []Session{
+		session(day(13, 9), 400),
+		session(day(15, 9), 600),
+		session(day(16, 9), 300),
+		session(day(16, 20), 500),
+		session(day(17, 8), 200),
+	}}
+	now = func() time.Time { return day(17, 12) }
+	t.Cleanup(func() { now = time.Now })
+
+	days := h.Days()
+	if len(days) != 4 || days[2].Date != "2026-10-16" || days[2].Words != 800 || days[2].Time != time.Hour {
+		t.Errorf("Days() = %+v", days)
+	}
+	if h.Today() != 200 {
+		t.Errorf("Today() = %d, want 200", h.Today())
+	}
+	if got := h.Streak(0); got != 3 {
+		t.Errorf("Streak(0) = %d, want 3", got)
+	}
+	// Today's 200 words miss a goal of 500, but the day is not over
+	if got := h.Streak(500); got != 2 {
+		t.Errorf("Streak(500) = %d, want 2", got)
+	}
+	if got := h.Pace(); got != 800 {
+		t.Errorf("Pace() = %d, want 800", got)
+	}
+
+	// Recording a session again updates it
+	s := session(day(17, 13), 100)
+	h.Record(&s)
+	s.Chapters[0].Added = 150
+	h.Record(&s)
+	h.Record(&Session{}) // nothing written
+	if len(h.Sessions) != 6 || h.Today() != 350 {
+		t.Errorf("after Record: %d sessions, today %d", len(h.Sessions), h.Today())
+	}
+}
+
+func TestSaveHistory(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "book.json")
+	if h, err := LoadHistory(name); err != nil || len(h.Sessions) != 0 {
+		t.Fatalf("LoadHistory() on a fresh project = %+v, %v", h, err)
+	}
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	h := &History{
+		Sessions: []Session{{Start: start, End: start.Add(time.Hour), Chapters: []ChapterWords{{"One", 10, 2}}}},
+		Sprints:  []Sprint{{Start: start, End: start.Add(10 * time.Minute), Minutes: 25, Words: 8}},
+	}
+	if err := SaveHistory(name, h); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadHistory(name)
+	if err != nil || len(loaded.Sessions) != 1 || loaded.Sessions[0].Words() != 8 || !loaded.Sessions[0].End.Equal(start.Add(time.Hour)) {
+		t.Errorf("LoadHistory() = %+v, %v", loaded, err)
+	}
+	if len(loaded.Sprints) != 1 || loaded.Sprints[0].Words != 8 || loaded.Sprints[0].Finished() {
+		t.Errorf("Sprints = %+v, want one stopped early", loaded.Sprints)
+	}
+}
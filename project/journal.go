@@ -0,0 +1,180 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// JournalInterval is how often the editor writes unsaved edits to the
+// recovery journal
+const JournalInterval = 5 * time.Second
+
+// Journal is a snapshot of a project with unsaved edits, kept next to it
+// between saves so a crash or dropped connection loses at most a few seconds
+// of work. A clean exit removes it; one left behind by a process that is no
+// longer running marks a session that can be recovered.
+type Journal struct {
+	Filename string // project file the edits belong to; empty when untitled
+	PID      int    // process that wrote the journal
+	Updated  time.Time
+	State    EditorState
+	Project  *Project
+
+	Path string `json:"-"` // where the journal was read from
+}
+
+// JournalPath returns the journal file for a project. Untitled sessions get
+// one per process in the working directory.
+func JournalPath(projectPath string, pid int) string {
+	if projectPath == "" {
+		return sidePath(fmt.Sprintf("untitled-%d", pid), "recovery", ".json")
+	}
+	return sidePath(projectPath, "recovery", ".json")
+}
+
+// Fingerprint summarises the saved content of the project, so the editor
+// can tell whether anything changed since it was last saved or journaled
+func (p *Project) Fingerprint() string {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return string(sum[:])
+}
+
+// Write stores the journal at path
+func (j *Journal) Write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// ReadJournal reads the journal at path
+func ReadJournal(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, err
+	}
+	if j.Project == nil || len(j.Project.Chapters) == 0 {
+		return nil, ErrEmpty
+	}
+	if j.Project.Version > FormatVersion {
+		return nil, ErrNewerVersion
+	}
+	if len(j.Project.Wiki) == 0 {
+		j.Project.Wiki = []WikiEntry{{Title: "General"}}
+	}
+	j.Project.Filename = j.Filename
+	j.Path = path
+	return j, nil
+}
+
+// RemoveJournal deletes a journal file, ignoring one that is already gone
+func RemoveJournal(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Journaler writes and removes journals on a background goroutine, in the
+// order they were requested, so the editor never waits on the disk and a
+// removal is never overtaken by an earlier write
+type Journaler struct {
+	ops  chan func()
+	done chan struct{}
+}
+
+// NewJournaler starts a Journaler. Close stops it.
+func NewJournaler() *Journaler {
+	jr := &Journaler{ops: make(chan func(), 16), done: make(chan struct{})}
+	go func() {
+		defer close(jr.done)
+		for op := range jr.ops {
+			op()
+		}
+	}()
+	return jr
+}
+
+// Write queues j to be written at path. The journal and its project are
+// handed over: the caller must not modify them afterwards (see Clone).
+func (jr *Journaler) Write(j *Journal, path string) {
+	jr.ops <- func() { j.Write(path) }
+}
+
+// Remove queues the removal of the journal at path
+func (jr *Journaler) Remove(path string) {
+	jr.ops <- func() { RemoveJournal(path) }
+}
+
+// Close finishes the queued work and stops the Journaler
+func (jr *Journaler) Close() {
+	close(jr.ops)
+	<-jr.done
+}
+
+// FindJournals returns the recoverable journals in the .gowrite/recovery
+// directories under dirs, newest first. Journals of processes that are still
+// running belong to live sessions and are skipped.
+func FindJournals(dirs ...string) ([]*Journal, error) {
+	seen := make(map[string]bool)
+	var journals []*Journal
+	for _, dir := range dirs {
+		recoveryDir, err := filepath.Abs(filepath.Join(dir, SideDir, "recovery"))
+		if err != nil || seen[recoveryDir] {
+			continue
+		}
+		seen[recoveryDir] = true
+
+		entries, err := os.ReadDir(recoveryDir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+				continue
+			}
+			j, err := ReadJournal(filepath.Join(recoveryDir, e.Name()))
+			if err != nil || processAlive(j.PID) {
+				continue
+			}
+			journals = append(journals, j)
+		}
+	}
+	sort.Slice(journals, func(i, k int) bool { return journals[i].Updated.After(journals[k].Updated) })
+	return journals, nil
+}
+
+// processAlive reports whether pid is a running process. Where signals are
+// unsupported every other process counts as gone.
+func processAlive(pid int) bool {
+	if pid == os.Getpid() {
+		return true
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,122 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournalPath(t *testing.T) {
+	if got, want := JournalPath("", 42), filepath.Join(".gowrite", "recovery", "untitled-42.json"); got != want {
+		t.Errorf("JournalPath(untitled) = %q, want %q", got, want)
+	}
+	if got, want := JournalPath("/work/book.json", 42), "/work/.gowrite/recovery/book.json"; got != want {
+		t.Errorf("JournalPath(book) = %q, want %q", got, want)
+	}
+}
+
+func TestJournal_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	p := withChapters("One", "Two")
+	p.Chapters[1].Content = "Unsaved words"
+
+	j := Journal{
+		Filename: filepath.Join(dir, "book.json"),
+		PID:      os.Getpid(),
+		Updated:  time.Now(),
+		State:    EditorState{Chapter: 1, View: "main", Offset: 5},
+		Project:  p,
+	}
+	path := JournalPath(j.Filename, j.PID)
+	if err := j.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got, err := ReadJournal(path)
+	if err != nil {
+		t.Fatalf("ReadJournal() error = %v", err)
+	}
+	if got.Project.Chapters[1].Content != "Unsaved words" || got.State != j.State {
+		t.Errorf("ReadJournal() = %+v, project %+v", got, got.Project.Chapters)
+	}
+	if got.Project.Filename != j.Filename || got.Path != path {
+		t.Errorf("Filename = %q, Path = %q", got.Project.Filename, got.Path)
+	}
+
+	if err := RemoveJournal(path); err != nil {
+		t.Fatalf("RemoveJournal() error = %v", err)
+	}
+	if err := RemoveJournal(path); err != nil {
+		t.Errorf("RemoveJournal() on missing journal error = %v", err)
+	}
+}
+
+func TestFindJournals(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, pid int, updated time.Time) {
+		j := Journal{Filename: name, PID: pid, Updated: updated, Project: New()}
+		if err := j.Write(JournalPath(name, pid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base := time.Date(2026, 10, 17, 15, 0, 0, 0, time.Local)
+	dead := 1 << 30 // no such process
+	write(filepath.Join(dir, "old.json"), dead, base)
+	write(filepath.Join(dir, "new.json"), dead, base.Add(time.Minute))
+	write(filepath.Join(dir, "live.json"), os.Getpid(), base.Add(2*time.Minute))
+
+	journals, err := FindJournals(dir, dir)
+	if err != nil {
+		t.Fatalf("FindJournals() error = %v", err)
+	}
+	var got []string
+	for _, j := range journals {
+		got = append(got, filepath.Base(j.Filename))
+	}
+	if want := []string{"new.json", "old.json"}; !equal(got, want) {
+		t.Errorf("FindJournals() = %v, want %v", got, want)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	p := New()
+	sum := p.Fingerprint()
+	p.CurrentWiki = 0
+	if p.Fingerprint() != sum {
+		t.Error("Fingerprint() changed without an edit")
+	}
+	p.Chapters[0].Content = "x"
+	if p.Fingerprint() == sum {
+		t.Error("Fingerprint() unchanged after an edit")
+	}
+}
+
+func TestJournaler(t *testing.T) {
+	dir := t.TempDir()
+	path := JournalPath(filepath.Join(dir, "book.json"), 1)
+	p := New()
+	p.Chapters[0].Content = "first"
+
+	jr := NewJournaler()
+	jr.Write(&Journal{PID: 1, Project: p.Clone()}, path)
+	p.Chapters[0].Content = "edited while the journal is written"
+	jr.Close()
+
+	j, err := ReadJournal(path)
+	if err != nil {
+		t.Fatalf("ReadJournal() error = %v", err)
+	}
+	if j.Project.Chapters[0].Content != "first" {
+		t.Errorf("journaled content = %q, want the snapshot", j.Project.Chapters[0].Content)
+	}
+
+	// A removal queued after a write must win
+	jr = NewJournaler()
+	jr.Write(&Journal{PID: 1, Project: p.Clone()}, path)
+	jr.Remove(path)
+	jr.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("journal still exists after Remove: %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+// Package project holds the gowrite manuscript model: chapters, the Story
+// Wiki and the operations the editor (or any other tool) performs on them.
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Chapter represents a section of the document
+type Chapter struct {
+	Title   string
+	Content string
+	Notes   string
+	Target  int
+
+	// The files a directory project keeps the text and notes in
+	file, notesFile string
+}
+
+// WikiEntry represents a single item in the Story Wiki
+type WikiEntry struct {
+	Title   string
+	Content string
+
+	file string // in a directory project
+}
+
+// Metadata describes the manuscript itself, for exports
+type Metadata struct {
+	Title   string `json:",omitempty"`
+	Author  string `json:",omitempty"`
+	Contact string `json:",omitempty"` // legal name, address, email; one per line
+}
+
+// Writing modes: how chapter text is read and exported
+const (
+	ModeProse      = ""           // paragraphs of prose
+	ModeScreenplay = "screenplay" // a script in Fountain markup
+)
+
+// Project represents the full save file structure (Chapters + Wiki)
+type Project struct {
+	Version  int    // FormatVersion of the file
+	Mode     string `json:",omitempty"` // ModeProse or ModeScreenplay
+	Metadata Metadata
+	Target   int    `json:",omitempty"` // word goal for the whole manuscript
+	Daily    int    `json:",omitempty"` // words a day to aim for
+	Deadline string `json:",omitempty"` // date to reach Target by, as DeadlineFormat
+	Chapters []Chapter
+	Wiki     []WikiEntry
+
+	// Backups overrides the default BackupPolicy when set
+	Backups *BackupPolicy `json:",omitempty"`
+
+	// Editor state, never written to disk
+	Filename       string `json:"-"`
+	CurrentChapter int    `json:"-"`
+	CurrentWiki    int    `json:"-"`
+}
+
+// Errors returned by Project operations
+var (
+	ErrInvalidChapter = errors.New("invalid chapter")
+	ErrLastChapter    = errors.New("cannot delete only chapter")
+	ErrInvalidWiki    = errors.New("invalid wiki entry")
+	ErrLastWiki       = errors.New("cannot delete the only wiki entry")
+	ErrNoFilename     = errors.New("please provide a filename: 'save <name>'")
+	ErrEmpty          = errors.New("file empty or corrupt")
+)
+
+// New returns a project with a single blank chapter and wiki entry
+func New() *Project {
+	return &Project{
+		Version:  FormatVersion,
+		Chapters: []Chapter{{Title: "The Beginning"}},
+		Wiki:     []WikiEntry{{Title: "General Notes"}},
+	}
+}
+
+// Clone returns a deep copy of the project. The editor owns its project on
+// the UI goroutine; background work such as journaling is handed a clone.
+func (p *Project) Clone() *Project {
+	c := *p
+	c.Chapters = append([]Chapter(nil), p.Chapters...)
+	c.Wiki = append([]WikiEntry(nil), p.Wiki...)
+	if p.Backups != nil {
+		policy := *p.Backups
+		c.Backups = &policy
+	}
+	return &c
+}
+
+// Name returns the manuscript title, falling back to the project file name
+func (p *Project) Name() string {
+	if p.Metadata.Title != "" {
+		return p.Metadata.Title
+	}
+	if p.Filename != "" {
+		return projectName(p.Filename)
+	}
+	return "Untitled"
+}
+
+// Chapter returns the current chapter
+func (p *Project) Chapter() *Chapter {
+	return &p.Chapters[p.CurrentChapter]
+}
+
+// WikiEntry returns the current wiki entry
+func (p *Project) WikiEntry() *WikiEntry {
+	return &p.Wiki[p.CurrentWiki]
+}
+
+// AddChapter appends a new chapter and returns its index
+func (p *Project) AddChapter(title string) int {
+	p.Chapters = append(p.Chapters, Chapter{Title: title})
+	return len(p.Chapters) - 1
+}
+
+// ImportChapters appends chapters and returns the index of the first. A
+// project holding only its blank starting chapter is replaced outright.
+func (p *Project) ImportChapters(chapters []Chapter) int {
+	if len(chapters) > 0 && len(p.Chapters) == 1 && strings.TrimSpace(p.Chapters[0].Content) == "" && strings.TrimSpace(p.Chapters[0].Notes) == "" {
+		p.Chapters = p.Chapters[:0]
+		p.CurrentChapter = 0
+	}
+	first := len(p.Chapters)
+	p.Chapters = append(p.Chapters, chapters...)
+	return first
+}
+
+// RenameChapter changes the title of chapter i
+func (p *Project) RenameChapter(i int, title string) error {
+	if i < 0 || i >= len(p.Chapters) {
+		return ErrInvalidChapter
+	}
+	p.Chapters[i].Title = title
+	return nil
+}
+
+// DeleteChapter removes chapter i, keeping CurrentChapter pointing at the
+// same chapter where possible
+func (p *Project) DeleteChapter(i int) error {
+	if len(p.Chapters) <= 1 {
+		return ErrLastChapter
+	}
+	if i < 0 || i >= len(p.Chapters) {
+		return ErrInvalidChapter
+	}
+
+	p.Chapters = append(p.Chapters[:i], p.Chapters[i+1:]...)
+	if i < p.CurrentChapter {
+		p.CurrentChapter--
+	} else if i == p.CurrentChapter && p.CurrentChapter >= len(p.Chapters) {
+		p.CurrentChapter = len(p.Chapters) - 1
+	}
+	return nil
+}
+
+// MoveChapter moves chapter from to position to, shifting the chapters in
+// between. CurrentChapter follows the chapter it pointed at.
+func (p *Project) MoveChapter(from, to int) error {
+	if from < 0 || from >= len(p.Chapters) || to < 0 || to >= len(p.Chapters) {
+		return ErrInvalidChapter
+	}
+	if from == to {
+		return nil
+	}
+
+	moved := p.Chapters[from]
+	if from < to {
+		copy(p.Chapters[from:to], p.Chapters[from+1:to+1])
+	} else {
+		copy(p.Chapters[to+1:from+1], p.Chapters[to:from])
+	}
+	p.Chapters[to] = moved
+
+	switch {
+	case p.CurrentChapter == from:
+		p.CurrentChapter = to
+	case from < p.CurrentChapter && p.CurrentChapter <= to:
+		p.CurrentChapter--
+	case to <= p.CurrentChapter && p.CurrentChapter < from:
+		p.CurrentChapter++
+	}
+	return nil
+}
+
+// DuplicateChapter copies chapter i into a new chapter right after it and
+// returns the copy's index. CurrentChapter follows the chapter it pointed at.
+func (p *Project) DuplicateChapter(i int) (int, error) {
+	if i < 0 || i >= len(p.Chapters) {
+		return 0, ErrInvalidChapter
+	}
+	dup := p.Chapters[i]
+	dup.Title += " (copy)"
+	dup.file, dup.notesFile = "", ""
+	p.Chapters = append(p.Chapters[:i+1], append([]Chapter{dup}, p.Chapters[i+1:]...)...)
+	if p.CurrentChapter > i {
+		p.CurrentChapter++
+	}
+	return i + 1, nil
+}
+
+// AddWiki appends a new wiki entry and returns its index
+func (p *Project) AddWiki(title string) int {
+	p.Wiki = append(p.Wiki, WikiEntry{Title: title})
+	return len(p.Wiki) - 1
+}
+
+// ImportWiki appends wiki entries and returns the index of the first. A
+// wiki holding only its blank starting entry is replaced outright.
+func (p *Project) ImportWiki(entries []WikiEntry) int {
+	if len(entries) > 0 && len(p.Wiki) == 1 && strings.TrimSpace(p.Wiki[0].Content) == "" {
+		p.Wiki = p.Wiki[:0]
+		p.CurrentWiki = 0
+	}
+	first := len(p.Wiki)
+	p.Wiki = append(p.Wiki, entries...)
+	return first
+}
+
+// RenameWiki changes the title of wiki entry i
+func (p *Project) RenameWiki(i int, title string) error {
+	if i < 0 || i >= len(p.Wiki) {
+		return ErrInvalidWiki
+	}
+	p.Wiki[i].Title = title
+	return nil
+}
+
+// DeleteWiki removes wiki entry i, keeping CurrentWiki valid
+func (p *Project) DeleteWiki(i int) error {
+	if len(p.Wiki) <= 1 {
+		return ErrLastWiki
+	}
+	if i < 0 || i >= len(p.Wiki) {
+		return ErrInvalidWiki
+	}
+
+	p.Wiki = append(p.Wiki[:i], p.Wiki[i+1:]...)
+	if i < p.CurrentWiki {
+		p.CurrentWiki--
+	} else if i == p.CurrentWiki && p.CurrentWiki >= len(p.Wiki) {
+		p.CurrentWiki = len(p.Wiki) - 1
+	}
+	return nil
+}
+
+// ApplyStructure replaces every chapter with the named template
+func (p *Project) ApplyStructure(name string) error {
+	chapters, err := Structure(name)
+	if err != nil {
+		return err
+	}
+	p.Chapters = chapters
+	p.CurrentChapter = 0
+	return nil
+}
+
+// Filename normalises a user supplied project name to a .json path, or for
+// a directory project (see IsDir) to a path ending in a separator
+func Filename(name string) string {
+	if IsDir(name) {
+		return dirName(name)
+	}
+	if !strings.HasSuffix(name, ".json") {
+		name += ".json"
+	}
+	return name
+}
+
+// Save writes the project as JSON. An empty filename reuses the name the
+// project was last loaded from or saved to. The previous version of the
+// file is backed up according to the project's BackupPolicy, and the new
+// one replaces it atomically.
+func (p *Project) Save(filename string) error {
+	if filename == "" {
+		if p.Filename == "" {
+			return ErrNoFilename
+		}
+		filename = p.Filename
+	}
+	filename = Filename(filename)
+
+	if err := p.backup(filename, false); err != nil {
+		return fmt.Errorf("backup failed, file not saved: %w", err)
+	}
+	if err := p.write(filename); err != nil {
+		return err
+	}
+
+	p.Filename = filename
+	return nil
+}
+
+// write stores the project at filename, as JSON or as a directory project
+func (p *Project) write(filename string) error {
+	if IsDir(filename) {
+		return p.writeDir(filename)
+	}
+	p.Version = FormatVersion
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, data, 0644)
+}
+
+// Load reads a project file. Files in an older format are migrated, after
+// a copy of the original is kept with the backups; the file itself is
+// upgraded the next time the project is saved.
+func Load(filename string) (*Project, error) {
+	filename = Filename(filename)
+	if IsDir(filename) {
+		p, err := loadDir(filename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		p.Filename = filename
+		return p, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	p, version, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if version < FormatVersion {
+		if err := migrationBackup(filename, version, data); err != nil {
+			return nil, fmt.Errorf("%s: backup before upgrade failed: %w", filename, err)
+		}
+	}
+	p.Filename = filename
+	return p, nil
+}
+
+// Parse decodes project JSON in the current or any older format
+func Parse(data []byte) (*Project, error) {
+	p, _, err := parse(data)
+	return p, err
+}
+
+// parse decodes project JSON, returning the format version it was in
+func parse(data []byte) (*Project, int, error) {
+	data, version, err := migrate(data)
+	if err != nil {
+		return nil, version, err
+	}
+
+	p := &Project{}
+	if err := json.Unmarshal(data, p); err != nil || len(p.Chapters) == 0 {
+		return nil, version, ErrEmpty
+	}
+	p.Version = FormatVersion
+
+	// Ensure Wiki isn't empty if loading from old file
+	if len(p.Wiki) == 0 {
+		p.Wiki = []WikiEntry{{Title: "General"}}
+	}
+	return p, version, nil
+}
@@ -0,0 +1,321 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func titles(p *Project) []string {
+	var out []string
+	for _, c := range p.Chapters {
+		out = append(out, c.Title)
+	}
+	return out
+}
+
+func withChapters(names ...string) *Project {
+	p := New()
+	p.Chapters = nil
+	for _, n := range names {
+		p.AddChapter(n)
+	}
+	return p
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNew(t *testing.T) {
+	p := New()
+	if len(p.Chapters) != 1 || p.Chapters[0].Title != "The Beginning" {
+		t.Errorf("New() chapters = %v", titles(p))
+	}
+	if len(p.Wiki) != 1 {
+		t.Errorf("New() wiki entries = %d, want 1", len(p.Wiki))
+	}
+}
+
+func TestClone(t *testing.T) {
+	p := withChapters("A", "B")
+	p.Backups = &BackupPolicy{Keep: 5}
+	c := p.Clone()
+
+	c.Chapters[0].Content = "changed"
+	c.Wiki[0].Title = "changed"
+	c.Backups.Keep = 1
+	c.AddChapter("C")
+	if p.Chapters[0].Content != "" || p.Wiki[0].Title == "changed" || p.Backups.Keep != 5 || len(p.Chapters) != 2 {
+		t.Errorf("editing the clone changed the original: %+v", p)
+	}
+}
+
+func TestDeleteChapter(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     int
+		delete      int
+		wantTitles  []string
+		wantCurrent int
+	}{
+		{"before current", 2, 0, []string{"B", "C"}, 1},
+		{"after current", 0, 2, []string{"A", "B"}, 0},
+		{"current in middle", 1, 1, []string{"A", "C"}, 1},
+		{"current at end", 2, 2, []string{"A", "B"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := withChapters("A", "B", "C")
+			p.CurrentChapter = tt.current
+			if err := p.DeleteChapter(tt.delete); err != nil {
+				t.Fatalf("DeleteChapter() error = %v", err)
+			}
+			if !equal(titles(p), tt.wantTitles) {
+				t.Errorf("chapters = %v, want %v", titles(p), tt.wantTitles)
+			}
+			if p.CurrentChapter != tt.wantCurrent {
+				t.Errorf("CurrentChapter = %d, want %d", p.CurrentChapter, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestDeleteChapter_Errors(t *testing.T) {
+	p := withChapters("A")
+	if err := p.DeleteChapter(0); err != ErrLastChapter {
+		t.Errorf("deleting only chapter: error = %v, want %v", err, ErrLastChapter)
+	}
+	p.AddChapter("B")
+	if err := p.DeleteChapter(5); err != ErrInvalidChapter {
+		t.Errorf("deleting out of range: error = %v, want %v", err, ErrInvalidChapter)
+	}
+}
+
+func TestMoveChapter(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     int
+		from, to    int
+		wantTitles  []string
+		wantCurrent int
+	}{
+		{"down moves current", 0, 0, 2, []string{"B", "C", "A", "D"}, 2},
+		{"up moves current", 3, 3, 1, []string{"A", "D", "B", "C"}, 1},
+		{"down past current", 1, 0, 2, []string{"B", "C", "A", "D"}, 0},
+		{"up past current", 1, 3, 0, []string{"D", "A", "B", "C"}, 2},
+		{"unrelated", 0, 2, 3, []string{"A", "B", "D", "C"}, 0},
+		{"same position", 1, 1, 1, []string{"A", "B", "C", "D"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := withChapters("A", "B", "C", "D")
+			p.CurrentChapter = tt.current
+			if err := p.MoveChapter(tt.from, tt.to); err != nil {
+				t.Fatalf("MoveChapter() error = %v", err)
+			}
+			if !equal(titles(p), tt.wantTitles) {
+				t.Errorf("chapters = %v, want %v", titles(p), tt.wantTitles)
+			}
+			if p.CurrentChapter != tt.wantCurrent {
+				t.Errorf("CurrentChapter = %d, want %d", p.CurrentChapter, tt.wantCurrent)
+			}
+		})
+	}
+
+	p := withChapters("A", "B")
+	if err := p.MoveChapter(0, 2); err != ErrInvalidChapter {
+		t.Errorf("out of range move: error = %v, want %v", err, ErrInvalidChapter)
+	}
+}
+
+func TestDuplicateChapter(t *testing.T) {
+	p := withChapters("A", "B", "C")
+	p.Chapters[1].Content, p.Chapters[1].Target = "Text", 500
+	p.CurrentChapter = 2
+	i, err := p.DuplicateChapter(1)
+	if err != nil || i != 2 {
+		t.Fatalf("DuplicateChapter() = %d, %v", i, err)
+	}
+	if !equal(titles(p), []string{"A", "B", "B (copy)", "C"}) || p.Chapters[2].Content != "Text" || p.Chapters[2].Target != 500 {
+		t.Errorf("chapters = %+v", p.Chapters)
+	}
+	if p.CurrentChapter != 3 {
+		t.Errorf("CurrentChapter = %d, want 3", p.CurrentChapter)
+	}
+	if _, err := p.DuplicateChapter(4); err != ErrInvalidChapter {
+		t.Errorf("out of range duplicate: error = %v, want %v", err, ErrInvalidChapter)
+	}
+}
+
+func TestRenameChapter(t *testing.T) {
+	p := withChapters("A", "B")
+	if err := p.RenameChapter(1, "Second"); err != nil {
+		t.Fatalf("RenameChapter() error = %v", err)
+	}
+	if p.Chapters[1].Title != "Second" {
+		t.Errorf("title = %q, want %q", p.Chapters[1].Title, "Second")
+	}
+	if err := p.RenameChapter(-1, "x"); err != ErrInvalidChapter {
+		t.Errorf("RenameChapter(-1) error = %v, want %v", err, ErrInvalidChapter)
+	}
+}
+
+func TestImportChapters(t *testing.T) {
+	p := New()
+	if first := p.ImportChapters([]Chapter{{Title: "A"}, {Title: "B"}}); first != 0 || !equal(titles(p), []string{"A", "B"}) {
+		t.Errorf("into a blank project: first = %d, chapters = %v", first, titles(p))
+	}
+	if first := p.ImportChapters([]Chapter{{Title: "C"}}); first != 2 || !equal(titles(p), []string{"A", "B", "C"}) {
+		t.Errorf("appending: first = %d, chapters = %v", first, titles(p))
+	}
+
+	p = New()
+	p.Chapters[0].Notes = "keep me"
+	if first := p.ImportChapters([]Chapter{{Title: "A"}}); first != 1 {
+		t.Errorf("a chapter with notes was replaced")
+	}
+}
+
+func TestImportWiki(t *testing.T) {
+	p := New()
+	if first := p.ImportWiki([]WikiEntry{{Title: "Holmes"}}); first != 0 || len(p.Wiki) != 1 || p.Wiki[0].Title != "Holmes" {
+		t.Errorf("into a blank wiki: first = %d, wiki = %v", first, p.Wiki)
+	}
+	p.Wiki[0].Content = "A detective."
+	if first := p.ImportWiki([]WikiEntry{{Title: "Watson"}}); first != 1 || len(p.Wiki) != 2 {
+		t.Errorf("appending: first = %d, wiki = %v", first, p.Wiki)
+	}
+}
+
+func TestWikiOps(t *testing.T) {
+	p := New()
+	i := p.AddWiki("Villain")
+	if i != 1 || p.Wiki[1].Title != "Villain" {
+		t.Fatalf("AddWiki() = %d, wiki = %v", i, p.Wiki)
+	}
+	if err := p.RenameWiki(1, "Antagonist"); err != nil || p.Wiki[1].Title != "Antagonist" {
+		t.Errorf("RenameWiki() error = %v, title = %q", err, p.Wiki[1].Title)
+	}
+
+	p.CurrentWiki = 1
+	if err := p.DeleteWiki(1); err != nil {
+		t.Fatalf("DeleteWiki() error = %v", err)
+	}
+	if p.CurrentWiki != 0 {
+		t.Errorf("CurrentWiki = %d, want 0", p.CurrentWiki)
+	}
+	if err := p.DeleteWiki(0); err != ErrLastWiki {
+		t.Errorf("deleting only entry: error = %v, want %v", err, ErrLastWiki)
+	}
+}
+
+func TestApplyStructure(t *testing.T) {
+	p := withChapters("A", "B")
+	p.CurrentChapter = 1
+	if err := p.ApplyStructure("Hero"); err != nil {
+		t.Fatalf("ApplyStructure() error = %v", err)
+	}
+	if len(p.Chapters) != 12 || p.CurrentChapter != 0 {
+		t.Errorf("got %d chapters, current %d; want 12, 0", len(p.Chapters), p.CurrentChapter)
+	}
+	if err := p.ApplyStructure("nope"); err != ErrUnknownStructure {
+		t.Errorf("unknown structure: error = %v, want %v", err, ErrUnknownStructure)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "book")
+
+	p := withChapters("One", "Two")
+	p.Chapters[1].Content = "It was a dark and stormy night."
+	p.Chapters[1].Target = 1500
+	p.Target = 90000
+	p.Wiki[0].Content = "Victorian London"
+	if err := p.Save(name); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if p.Filename != name+".json" {
+		t.Errorf("Filename = %q, want %q", p.Filename, name+".json")
+	}
+
+	loaded, err := Load(name)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !equal(titles(loaded), []string{"One", "Two"}) {
+		t.Errorf("loaded chapters = %v", titles(loaded))
+	}
+	if loaded.Chapters[1].Content != p.Chapters[1].Content || loaded.Chapters[1].Target != 1500 || loaded.Target != 90000 {
+		t.Errorf("loaded chapter = %+v", loaded.Chapters[1])
+	}
+	if loaded.Wiki[0].Content != "Victorian London" {
+		t.Errorf("loaded wiki = %+v", loaded.Wiki)
+	}
+}
+
+func TestSave_NoFilename(t *testing.T) {
+	if err := New().Save(""); err != ErrNoFilename {
+		t.Errorf("Save(\"\") error = %v, want %v", err, ErrNoFilename)
+	}
+}
+
+func TestLoad_OldFormat(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "old.json")
+	data := `[{"Title": "Legacy", "Content": "Old text"}]`
+	if err := os.WriteFile(name, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(name)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(p.Chapters) != 1 || p.Chapters[0].Content != "Old text" {
+		t.Errorf("chapters = %+v", p.Chapters)
+	}
+	if len(p.Wiki) != 1 || p.Wiki[0].Title != "General" {
+		t.Errorf("wiki = %+v, want default entry", p.Wiki)
+	}
+}
+
+func TestParse_Corrupt(t *testing.T) {
+	for _, data := range []string{"", "{}", "[]", "not json"} {
+		if _, err := Parse([]byte(data)); err != ErrEmpty {
+			t.Errorf("Parse(%q) error = %v, want %v", data, err, ErrEmpty)
+		}
+	}
+}
+
+func TestEditorState(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "book.json")
+	if _, err := LoadState(name); !os.IsNotExist(err) {
+		t.Errorf("LoadState() on fresh project error = %v, want not-exist", err)
+	}
+
+	want := EditorState{Chapter: 3, View: "notes", Row: 2, Column: 7, Offset: 42, Centered: true, Theme: "light"}
+	if err := SaveState(name, want); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+	got, err := LoadState(name)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("LoadState() = %+v, want %+v", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(name), ".gowrite", "state", "book.json")); err != nil {
+		t.Errorf("state file not in .gowrite/state: %v", err)
+	}
+}
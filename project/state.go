@@ -0,0 +1,63 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SideDir is the directory, next to a project file, holding gowrite's
+// per-project bookkeeping
+const SideDir = ".gowrite"
+
+// EditorState is where the editor was when a project was last closed
+type EditorState struct {
+	Chapter  int
+	Wiki     int
+	View     string // "main", "notes" or "wiki"
+	Row      int    // cursor position in the active editor
+	Column   int
+	Offset   int // cursor byte offset, used to put the cursor back
+	Centered bool
+	Focus    bool
+	Theme    string
+}
+
+// sidePath returns the file used for kind (e.g. "state") bookkeeping of the
+// project at projectPath: <dir>/.gowrite/<kind>/<name><ext>
+func sidePath(projectPath, kind, ext string) string {
+	projectPath = filepath.Clean(projectPath) // directory projects end in a separator
+	return filepath.Join(filepath.Dir(projectPath), SideDir, kind, projectName(projectPath)+ext)
+}
+
+// projectName is the project file name without directory or extension
+func projectName(projectPath string) string {
+	file := filepath.Base(filepath.Clean(projectPath))
+	return strings.TrimSuffix(file, filepath.Ext(file))
+}
+
+// LoadState reads the saved editor state for a project. A project that was
+// never closed in the editor has no state and gets an os.ErrNotExist error.
+func LoadState(projectPath string) (EditorState, error) {
+	var st EditorState
+	data, err := os.ReadFile(sidePath(projectPath, "state", ".json"))
+	if err != nil {
+		return st, err
+	}
+	err = json.Unmarshal(data, &st)
+	return st, err
+}
+
+// SaveState records the editor state for a project
+func SaveState(projectPath string, st EditorState) error {
+	path := sidePath(projectPath, "state", ".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
@@ -0,0 +1,172 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"gowrite/project"
+)
+
+// The texts of a project a Place can be
+const (
+	Content = "content" // a chapter's text
+	Notes   = "notes"   // a chapter's Scene Notes
+	Wiki    = "wiki"    // a Story Wiki entry
+)
+
+// Place is one text of a project: the Content or Notes of chapter Index,
+// or wiki entry Index
+type Place struct {
+	Field string
+	Index int
+}
+
+// Text returns the text at the place in p
+func (pl Place) Text(p *project.Project) string {
+	switch pl.Field {
+	case Notes:
+		return p.Chapters[pl.Index].Notes
+	case Wiki:
+		return p.Wiki[pl.Index].Content
+	}
+	return p.Chapters[pl.Index].Content
+}
+
+// Set replaces the text at the place in p
+func (pl Place) Set(p *project.Project, text string) {
+	switch pl.Field {
+	case Notes:
+		p.Chapters[pl.Index].Notes = text
+	case Wiki:
+		p.Wiki[pl.Index].Content = text
+	default:
+		p.Chapters[pl.Index].Content = text
+	}
+}
+
+// exists reports whether p still has the place
+func (pl Place) exists(p *project.Project) bool {
+	if pl.Field == Wiki {
+		return pl.Index < len(p.Wiki)
+	}
+	return pl.Index < len(p.Chapters)
+}
+
+// Hit is a match in one text of a project
+type Hit struct {
+	Place
+	Match   []int  // as returned by Matcher.All
+	Line    int    // counted from 1
+	Context string // the line around the match
+	Before  int    // where the match starts in Context
+}
+
+// contextWidth is how much of a line is kept either side of a hit
+const contextWidth = 30
+
+// Hits returns the matches of m in text, which is at place
+func (m *Matcher) Hits(place Place, text string) []Hit {
+	var hits []Hit
+	line, lineStart := 1, 0
+	for _, match := range m.All(text) {
+		line += strings.Count(text[lineStart:match[0]], "\n")
+		if i := strings.LastIndexByte(text[:match[0]], '\n'); i >= 0 {
+			lineStart = i + 1
+		}
+		lineEnd := len(text)
+		if i := strings.IndexByte(text[match[0]:], '\n'); i >= 0 {
+			lineEnd = match[0] + i
+		}
+
+		before := trimLeft(text[lineStart:match[0]], contextWidth)
+		after := trimRight(text[min(match[1], lineEnd):lineEnd], contextWidth)
+		hits = append(hits, Hit{
+			Place:   place,
+			Match:   match,
+			Line:    line,
+			Context: before + text[match[0]:min(match[1], lineEnd)] + after,
+			Before:  len(before),
+		})
+	}
+	return hits
+}
+
+// Find returns the matches of m throughout p: each chapter's text and then
+// its notes, in chapter order, then the wiki
+func Find(p *project.Project, m *Matcher) []Hit {
+	var hits []Hit
+	for i, c := range p.Chapters {
+		hits = append(hits, m.Hits(Place{Content, i}, c.Content)...)
+		hits = append(hits, m.Hits(Place{Notes, i}, c.Notes)...)
+	}
+	for i, w := range p.Wiki {
+		hits = append(hits, m.Hits(Place{Wiki, i}, w.Content)...)
+	}
+	return hits
+}
+
+// Edit records a text of a project changed by ReplaceHits
+type Edit struct {
+	Place
+	Before, After string
+}
+
+// ReplaceHits replaces hits, found by Find, in p with template. The edits
+// it returns undo the whole replacement at once.
+func ReplaceHits(p *project.Project, m *Matcher, template string, hits []Hit) []Edit {
+	var edits []Edit
+	for len(hits) > 0 {
+		place := hits[0].Place
+		var matches [][]int
+		for len(hits) > 0 && hits[0].Place == place {
+			matches = append(matches, hits[0].Match)
+			hits = hits[1:]
+		}
+		before := place.Text(p)
+		after := m.Replace(before, template, matches)
+		if after != before {
+			place.Set(p, after)
+			edits = append(edits, Edit{place, before, after})
+		}
+	}
+	return edits
+}
+
+// Undo reverts edits made by ReplaceHits. A text changed again since, or
+// no longer there, is left alone; Undo returns how many were.
+func Undo(p *project.Project, edits []Edit) (kept int) {
+	for _, e := range edits {
+		if !e.exists(p) || e.Text(p) != e.After {
+			kept++
+			continue
+		}
+		e.Set(p, e.Before)
+	}
+	return kept
+}
+
+// trimLeft keeps the last n runes of s, marking a cut with an ellipsis
+func trimLeft(s string, n int) string {
+	i := len(s)
+	for ; n > 0 && i > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(s[:i])
+		i -= size
+	}
+	if i == 0 {
+		return s
+	}
+	return "…" + s[i:]
+}
+
+// trimRight keeps the first n runes of s, marking a cut with an ellipsis
+func trimRight(s string, n int) string {
+	i := 0
+	for ; n > 0 && i < len(s); n-- {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	if i == len(s) {
+		return s
+	}
+	return s[:i] + "…"
+}
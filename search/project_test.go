@@ -0,0 +1,88 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"gowrite/project"
+)
+
+func book() *project.Project {
+	p := project.New()
+	p.Chapters = []project.Chapter{
+		{Title: "One", Content: "Holmes knocked.\nWatson, said Holmes, is late.", Notes: "Holmes is tired"},
+		{Title: "Two", Content: "Nobody here."},
+	}
+	p.Wiki[0].Content = "Sherlock Holmes, detective"
+	return p
+}
+
+func TestFind(t *testing.T) {
+	m, _ := Compile("holmes", Options{})
+	hits := Find(book(), m)
+	want := []struct {
+		place   Place
+		line    int
+		context string
+	}{
+		{Place{Content, 0}, 1, "Holmes knocked."},
+		{Place{Content, 0}, 2, "Watson, said Holmes, is late."},
+		{Place{Notes, 0}, 1, "Holmes is tired"},
+		{Place{Wiki, 0}, 1, "Sherlock Holmes, detective"},
+	}
+	if len(hits) != len(want) {
+		t.Fatalf("Find() = %d hits, want %d: %+v", len(hits), len(want), hits)
+	}
+	for i, w := range want {
+		h := hits[i]
+		if h.Place != w.place || h.Line != w.line || h.Context != w.context || !strings.HasPrefix(h.Context[h.Before:], "Holmes") {
+			t.Errorf("hit %d = %+v, want %+v", i, h, w)
+		}
+	}
+}
+
+func TestHits_LongLine(t *testing.T) {
+	m, _ := Compile("needle", Options{})
+	text := strings.Repeat("é", 50) + " needle " + strings.Repeat("x", 50)
+	hits := m.Hits(Place{Content, 0}, text)
+	if len(hits) != 1 {
+		t.Fatalf("Hits() = %+v", hits)
+	}
+	want := "…" + strings.Repeat("é", 29) + " needle " + strings.Repeat("x", 29) + "…"
+	if hits[0].Context != want {
+		t.Errorf("Context = %q, want %q", hits[0].Context, want)
+	}
+}
+
+func TestReplaceHitsUndo(t *testing.T) {
+	p := book()
+	m, _ := Compile(`(\w+) Holmes`, Options{Regex: true})
+	hits := Find(p, m)
+	if len(hits) != 2 {
+		t.Fatalf("Find() = %+v", hits)
+	}
+	// Skip the first hit
+	edits := ReplaceHits(p, m, "Mr $1", hits[1:])
+	if len(edits) != 1 || p.Wiki[0].Content != "Mr Sherlock, detective" || p.Chapters[0].Content != book().Chapters[0].Content {
+		t.Fatalf("ReplaceHits() edits = %+v, project = %+v", edits, p)
+	}
+
+	if kept := Undo(p, edits); kept != 0 || p.Wiki[0].Content != book().Wiki[0].Content {
+		t.Errorf("Undo() kept %d, wiki = %q", kept, p.Wiki[0].Content)
+	}
+
+	// A text edited after the replace is not undone
+	edits = ReplaceHits(p, m, "Mr $1", hits)
+	p.Wiki[0].Content += " (retired)"
+	if kept := Undo(p, edits); kept != 1 || p.Chapters[0].Content != book().Chapters[0].Content {
+		t.Errorf("Undo() after an edit kept %d, chapter = %q", kept, p.Chapters[0].Content)
+	}
+}
+
+func TestReplace_Literal(t *testing.T) {
+	m, _ := Compile("cat", Options{})
+	text := "cat and cat"
+	if got := m.Replace(text, "$1 dog", m.All(text)); got != "$1 dog and $1 dog" {
+		t.Errorf("Replace() = %q", got)
+	}
+}
@@ -0,0 +1,140 @@
+// Package search finds and replaces text in a manuscript: a term matched
+// as typed or as a regular expression, with or without regard to case and
+// word boundaries, in one text or throughout a project.
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Options control how a term matches
+type Options struct {
+	CaseSensitive bool
+	WholeWord     bool
+	Regex         bool // the term is a regular expression
+}
+
+// String lists the options that are on, for the search bar title
+func (opt Options) String() string {
+	var on []string
+	if opt.CaseSensitive {
+		on = append(on, "case")
+	}
+	if opt.WholeWord {
+		on = append(on, "word")
+	}
+	if opt.Regex {
+		on = append(on, "regex")
+	}
+	return strings.Join(on, ", ")
+}
+
+// Matcher finds a term in text
+type Matcher struct {
+	re        *regexp.Regexp
+	wholeWord bool
+	regex     bool
+}
+
+// Compile turns a term into the Matcher that finds it
+func Compile(term string, opt Options) (*Matcher, error) {
+	if term == "" {
+		return nil, errors.New("nothing to search for")
+	}
+	expr := term
+	if !opt.Regex {
+		expr = regexp.QuoteMeta(term)
+	}
+	if !opt.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %v", term, err)
+	}
+	return &Matcher{re: re, wholeWord: opt.WholeWord, regex: opt.Regex}, nil
+}
+
+// All returns the byte offsets of every match in text. Empty matches, such
+// as those of "^" or "a*", are left out: there is nothing to select. With
+// WholeWord a match must not have a letter or digit either side of it
+// (\b would only know ASCII letters).
+func (m *Matcher) All(text string) [][]int {
+	var out [][]int
+	for _, loc := range m.re.FindAllStringSubmatchIndex(text, -1) {
+		if loc[1] == loc[0] || m.wholeWord && !wordAt(text, loc[0], loc[1]) {
+			continue
+		}
+		out = append(out, loc)
+	}
+	return out
+}
+
+// Expand returns the replacement for a match found by All: template as it
+// is, or for a regular expression with $1 and the like filled in from the
+// match
+func (m *Matcher) Expand(template, text string, match []int) string {
+	if !m.regex {
+		return template
+	}
+	return string(m.re.ExpandString(nil, template, text, match))
+}
+
+// Replace returns text with matches, found by All and in order, replaced
+// by template
+func (m *Matcher) Replace(text, template string, matches [][]int) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		b.WriteString(text[last:match[0]])
+		b.WriteString(m.Expand(template, text, match))
+		last = match[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// wordAt reports whether text[start:end] stands as whole words
+func wordAt(text string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	return !isWord(before) && !isWord(after)
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Next returns the index in matches of the first match starting at or
+// after from, wrapping round to the first match. It is -1 when there are
+// none.
+func Next(matches [][]int, from int) int {
+	if len(matches) == 0 {
+		return -1
+	}
+	for i, m := range matches {
+		if m[0] >= from {
+			return i
+		}
+	}
+	return 0
+}
+
+// Prev returns the index in matches of the last match starting before
+// before, wrapping round to the last match. It is -1 when there are none.
+func Prev(matches [][]int, before int) int {
+	if len(matches) == 0 {
+		return -1
+	}
+	for i := len(matches) - 1; i >= 0; i-- {
+		if matches[i][0] < before {
+			return i
+		}
+	}
+	return len(matches) - 1
+}
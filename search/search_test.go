@@ -0,0 +1,79 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	text := "The cat sat. A Cat? Cats concatenate; café caf."
+	tests := []struct {
+		term string
+		opt  Options
+		want [][]int
+	}{
+		{"cat", Options{}, [][]int{{4, 7}, {15, 18}, {20, 23}, {28, 31}}},
+		{"cat", Options{CaseSensitive: true}, [][]int{{4, 7}, {28, 31}}},
+		{"cat", Options{WholeWord: true}, [][]int{{4, 7}, {15, 18}}},
+		{"caf", Options{WholeWord: true}, [][]int{{44, 47}}},
+		{"c.t", Options{}, nil},
+		{`[cs]at\b`, Options{Regex: true}, [][]int{{4, 7}, {8, 11}, {15, 18}}},
+		{"^|sat", Options{Regex: true}, [][]int{{8, 11}}},
+	}
+	for _, tt := range tests {
+		m, err := Compile(tt.term, tt.opt)
+		if err != nil {
+			t.Fatalf("Compile(%q, %+v) error = %v", tt.term, tt.opt, err)
+		}
+		var got [][]int
+		for _, loc := range m.All(text) {
+			got = append(got, loc[:2])
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("All(%q, %+v) = %v, want %v", tt.term, tt.opt, got, tt.want)
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	if _, err := Compile("", Options{}); err == nil {
+		t.Error("an empty term did not fail")
+	}
+	if _, err := Compile("(", Options{Regex: true}); err == nil {
+		t.Error("a bad regular expression did not fail")
+	}
+	if _, err := Compile("(", Options{}); err != nil {
+		t.Errorf("a plain term was read as a regular expression: %v", err)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	text := "Smith, John"
+	m, _ := Compile(`(\w+), (\w+)`, Options{Regex: true})
+	match := m.All(text)[0]
+	if got := m.Expand("$2 $1", text, match); got != "John Smith" {
+		t.Errorf("Expand() = %q", got)
+	}
+}
+
+func TestNextPrev(t *testing.T) {
+	matches := [][]int{{2, 4}, {10, 12}, {20, 22}}
+	for _, tt := range []struct{ from, next, prev int }{
+		{0, 0, 2},
+		{2, 0, 2},
+		{3, 1, 0},
+		{10, 1, 0},
+		{11, 2, 1},
+		{25, 0, 2},
+	} {
+		if got := Next(matches, tt.from); got != tt.next {
+			t.Errorf("Next(%d) = %d, want %d", tt.from, got, tt.next)
+		}
+		if got := Prev(matches, tt.from); got != tt.prev {
+			t.Errorf("Prev(%d) = %d, want %d", tt.from, got, tt.prev)
+		}
+	}
+	if Next(nil, 0) != -1 || Prev(nil, 0) != -1 {
+		t.Error("no matches did not give -1")
+	}
+}
@@ -72,7 +72,7 @@ echo ""
 
 # Build the application
 echo "🔨 Building application..."
-go build -o gowrite gowrite.go
+go build -o gowrite .
 if [ $? -eq 0 ]; then
     echo "✅ Build successful"
 else
@@ -8,7 +8,7 @@ echo ""
 
 # Step 1: Build check
 echo "✓ Step 1: Building application..."
-go build -o gowrite gowrite.go
+go build -o gowrite .
 if [ -f gowrite ]; then
     echo "  ✅ Build successful ($(ls -lh gowrite | awk '{print $5}'))"
 else